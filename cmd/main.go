@@ -6,6 +6,12 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strings"
+
+	// Backends de armazenamento disponíveis
+	_ "fileblobs/pkg/azure"
+	_ "fileblobs/pkg/filesystem"
+	_ "fileblobs/pkg/s3"
 )
 
 func main() {
@@ -16,12 +22,17 @@ func main() {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Permite requisições do mesmo origem
 			w.Header().Set("Access-Control-Allow-Origin", r.Header.Get("Origin"))
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, POST, PATCH, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Tus-Resumable, Upload-Length, Upload-Metadata, Upload-Offset, X-HTTP-Method-Override, X-Requested-With")
+			w.Header().Set("Access-Control-Expose-Headers", "Location, Tus-Resumable, Tus-Version, Tus-Extension, Tus-Max-Size, Upload-Offset, Upload-Length")
 			w.Header().Set("Access-Control-Allow-Credentials", "true")
 
 			// Responde imediatamente às requisições OPTIONS (preflight)
 			if r.Method == "OPTIONS" {
+				// Clientes tus usam o OPTIONS para descobrir as capacidades do servidor
+				if strings.HasPrefix(r.URL.Path, "/uploads") {
+					handlers.SetTusCapabilities(w)
+				}
 				w.WriteHeader(http.StatusOK)
 				return
 			}
@@ -42,12 +53,37 @@ func main() {
 	mux.HandleFunc("/edit-account", handlers.AuthMiddleware(handlers.EditAccountHandler))
 	mux.HandleFunc("/select-account", handlers.AuthMiddleware(handlers.SelectAccountHandler))
 
+	// Os handlers de arquivos recebem o armazenamento por injeção,
+	// resolvido a partir da conta selecionada em cada requisição
+	files := handlers.NewFileHandlers(handlers.SessionStore)
+
 	// File handling routes - protected by auth middleware
-	mux.HandleFunc("/", handlers.AuthMiddleware(handlers.ListFilesHandler))
-	mux.HandleFunc("/download", handlers.AuthMiddleware(handlers.DownloadHandler))
-	mux.HandleFunc("/download-folder", handlers.AuthMiddleware(handlers.DownloadFolderHandler))
-	mux.HandleFunc("/download-multiple", handlers.AuthMiddleware(handlers.DownloadMultipleHandler))
-	mux.HandleFunc("/upload", handlers.AuthMiddleware(handlers.UploadHandler))
+	mux.HandleFunc("/", handlers.AuthMiddleware(files.ListFilesHandler))
+	mux.HandleFunc("/download", handlers.AuthMiddleware(files.DownloadHandler))
+	mux.HandleFunc("/preview", handlers.AuthMiddleware(files.PreviewHandler))
+	mux.HandleFunc("/view", handlers.AuthMiddleware(files.ViewHandler))
+	mux.HandleFunc("/thumbnail", handlers.AuthMiddleware(files.ThumbnailHandler))
+	mux.HandleFunc("/versions", handlers.AuthMiddleware(files.VersionsHandler))
+	mux.HandleFunc("/download-version", handlers.AuthMiddleware(files.VersionDownloadHandler))
+	mux.HandleFunc("/restore-version", handlers.AuthMiddleware(files.RestoreVersionHandler))
+	mux.HandleFunc("/recycle-bin", handlers.AuthMiddleware(files.RecycleBinHandler))
+	mux.HandleFunc("/undelete", handlers.AuthMiddleware(files.UndeleteHandler))
+	mux.HandleFunc("/download-folder", handlers.AuthMiddleware(files.DownloadFolderHandler))
+	mux.HandleFunc("/download-multiple", handlers.AuthMiddleware(files.DownloadMultipleHandler))
+	mux.HandleFunc("/search", handlers.AuthMiddleware(files.SearchHandler))
+	mux.HandleFunc("/upload", handlers.AuthMiddleware(files.UploadHandler))
+	mux.HandleFunc("/create-folder", handlers.AuthMiddleware(files.CreateFolderHandler))
+	mux.HandleFunc("/delete", handlers.AuthMiddleware(files.DeleteHandler))
+	mux.HandleFunc("/move", handlers.AuthMiddleware(files.MoveHandler))
+	mux.HandleFunc("/copy-to-account", handlers.AuthMiddleware(files.CopyToAccountHandler))
+	mux.HandleFunc("/set-tier", handlers.AuthMiddleware(files.SetTierHandler))
+	mux.HandleFunc("/tags", handlers.AuthMiddleware(files.TagsHandler))
+	mux.HandleFunc("/save-tags", handlers.AuthMiddleware(files.SaveTagsHandler))
+	mux.HandleFunc("/bulk-tags", handlers.AuthMiddleware(files.BulkTagsHandler))
+	mux.HandleFunc("/tag-search", handlers.AuthMiddleware(files.TagSearchHandler))
+	mux.HandleFunc("/job-status", handlers.AuthMiddleware(handlers.JobStatusHandler))
+	mux.HandleFunc("/uploads", handlers.AuthMiddleware(files.TusHandler))
+	mux.HandleFunc("/uploads/", handlers.AuthMiddleware(files.TusHandler))
 	mux.HandleFunc("/download-zip", handlers.AuthMiddleware(handlers.DownloadZipHandler))
 
 	// Static files
@@ -3,7 +3,7 @@ package handlers
 import (
 	"encoding/json"
 	"fileblobs/internal/repository"
-	"fileblobs/pkg/azure"
+	"fileblobs/pkg/storage"
 	"fmt"
 	"html/template"
 	"io"
@@ -13,12 +13,12 @@ import (
 	"strings"
 )
 
-var loginTmpl = template.Must(template.ParseFiles("web/templates/login.html"))
-var storageAccountsTmpl = template.Must(template.ParseFiles("web/templates/storage_accounts.html"))
-var addAccountTmpl = template.Must(template.ParseFiles("web/templates/add_account.html"))
-var editAccountTmpl = template.Must(template.ParseFiles("web/templates/edit_account.html"))
-var accessDeniedTmpl = template.Must(template.ParseFiles("web/templates/access_denied.html"))
-var logoutTmpl = template.Must(template.ParseFiles("web/templates/logout.html"))
+var loginTmpl = template.Must(template.ParseFiles(templateFile("login.html")))
+var storageAccountsTmpl = template.Must(template.ParseFiles(templateFile("storage_accounts.html")))
+var addAccountTmpl = template.Must(template.ParseFiles(templateFile("add_account.html")))
+var editAccountTmpl = template.Must(template.ParseFiles(templateFile("edit_account.html")))
+var accessDeniedTmpl = template.Must(template.ParseFiles(templateFile("access_denied.html")))
+var logoutTmpl = template.Must(template.ParseFiles(templateFile("logout.html")))
 
 func LoginHandler(w http.ResponseWriter, r *http.Request) {
 	// Check if user is already logged in
@@ -208,20 +208,10 @@ func AddAccountHandler(w http.ResponseWriter, r *http.Request) {
 
 	if r.Method == http.MethodPost {
 		// Process the form submission
-		name := r.FormValue("name")
-		description := r.FormValue("description")
-		accountName := r.FormValue("accountName")
-		useDefaultKey := r.FormValue("useDefaultKey")
-		accountKey := r.FormValue("accountKey")
-		containerName := r.FormValue("containerName")
-
-		// Se selecionou usar a chave padrão
-		if useDefaultKey == "yes" {
-			accountKey = defaultAccountKey
-		}
+		newAccount := storageAccountFromForm(r, defaultAccountKey)
 
 		// Validate inputs
-		if name == "" || accountName == "" || accountKey == "" || containerName == "" {
+		if !hasRequiredAccountFields(newAccount) {
 			addAccountTmpl.Execute(w, map[string]interface{}{
 				"Error":                "Todos os campos são obrigatórios",
 				"DefaultAccountName":   defaultAccountName,
@@ -230,15 +220,6 @@ func AddAccountHandler(w http.ResponseWriter, r *http.Request) {
 			})
 			return
 		}
-		// Create new storage account
-		newAccount := repository.StorageAccount{
-			Name:          name,
-			Description:   description,
-			AccountName:   accountName,
-			AccountKey:    accountKey,
-			ContainerName: containerName,
-		}
-
 		// Add to repository
 		err := repository.AddStorageAccount(newAccount)
 		if err != nil {
@@ -294,20 +275,12 @@ func EditAccountHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodPost {
 		// Processar envio do formulário
 		originalName := r.FormValue("originalName")
-		name := r.FormValue("name")
-		description := r.FormValue("description")
-		azureAccountName := r.FormValue("accountName")
-		useDefaultKey := r.FormValue("useDefaultKey")
-		accountKey := r.FormValue("accountKey")
-		containerName := r.FormValue("containerName")
 
-		// Se selecionou usar a chave padrão
-		if useDefaultKey == "yes" {
-			accountKey = defaultAccountKey
-		}
+		// Criar objeto da conta atualizada
+		updatedAccount := storageAccountFromForm(r, defaultAccountKey)
 
 		// Validar entradas
-		if name == "" || azureAccountName == "" || accountKey == "" || containerName == "" {
+		if !hasRequiredAccountFields(updatedAccount) {
 			account, found := repository.GetStorageAccountByName(originalName)
 			if !found {
 				http.Error(w, "Conta não encontrada", http.StatusNotFound)
@@ -323,21 +296,13 @@ func EditAccountHandler(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Verificar se está tentando editar a conta padrão
-		if originalName == "Conta Padrão" {
+		if originalName == repository.DefaultAccountName {
 			http.Error(w, "Não é permitido editar a conta padrão", http.StatusForbidden)
 			return
 		}
 
-		// Criar objeto da conta atualizada
-		updatedAccount := repository.StorageAccount{
-			Name:          name,
-			Description:   description,
-			AccountName:   azureAccountName,
-			AccountKey:    accountKey,
-			ContainerName: containerName,
-		}
-
 		// Atualizar no repositório
+		previousAccount, _ := repository.GetStorageAccountByName(originalName)
 		err := repository.UpdateStorageAccount(originalName, updatedAccount)
 		if err != nil {
 			account, _ := repository.GetStorageAccountByName(originalName)
@@ -349,6 +314,9 @@ func EditAccountHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		// Descartar os clientes em cache da configuração anterior
+		storage.Evict(previousAccount.StorageConfig())
+
 		// Redirecionar para a lista de contas
 		http.Redirect(w, r, "/storage-accounts", http.StatusSeeOther)
 		return
@@ -362,7 +330,7 @@ func EditAccountHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Verificar se está tentando editar a conta padrão
-	if account.Name == "Conta Padrão" {
+	if account.Name == repository.DefaultAccountName {
 		http.Error(w, "Não é permitido editar a conta padrão", http.StatusForbidden)
 		return
 	}
@@ -374,6 +342,56 @@ func EditAccountHandler(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// storageAccountFromForm monta uma conta a partir do formulário de cadastro/edição,
+// descartando os campos que não pertencem ao tipo escolhido
+func storageAccountFromForm(r *http.Request, defaultAccountKey string) repository.StorageAccount {
+	account := repository.StorageAccount{
+		Name:        r.FormValue("name"),
+		Description: r.FormValue("description"),
+		Type:        r.FormValue("type"),
+	}
+
+	switch account.StorageType() {
+	case storage.TypeFilesystem:
+		account.RootPath = r.FormValue("rootPath")
+	case storage.TypeS3:
+		account.Endpoint = r.FormValue("endpoint")
+		account.Region = r.FormValue("region")
+		account.AccessKeyID = r.FormValue("accessKeyId")
+		account.SecretAccessKey = r.FormValue("secretAccessKey")
+		account.Bucket = r.FormValue("bucket")
+		account.PathStyle = r.FormValue("pathStyle") == "on"
+	default:
+		account.AccountName = r.FormValue("accountName")
+		account.AccountKey = r.FormValue("accountKey")
+		account.ContainerName = r.FormValue("containerName")
+		account.Endpoint = r.FormValue("azureEndpoint")
+
+		// Se selecionou usar a chave padrão
+		if r.FormValue("useDefaultKey") == "yes" {
+			account.AccountKey = defaultAccountKey
+		}
+	}
+
+	return account
+}
+
+// hasRequiredAccountFields verifica se os campos obrigatórios do tipo da conta foram preenchidos
+func hasRequiredAccountFields(account repository.StorageAccount) bool {
+	if account.Name == "" {
+		return false
+	}
+
+	switch account.StorageType() {
+	case storage.TypeFilesystem:
+		return account.RootPath != ""
+	case storage.TypeS3:
+		return account.AccessKeyID != "" && account.SecretAccessKey != "" && account.Bucket != ""
+	default:
+		return account.AccountName != "" && account.AccountKey != "" && account.ContainerName != ""
+	}
+}
+
 func SelectAccountHandler(w http.ResponseWriter, r *http.Request) {
 	// Check if user is authenticated
 	_, authenticated := getSessionUser(r)
@@ -390,14 +408,14 @@ func SelectAccountHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Find account in repository
-	account, found := repository.GetStorageAccountByName(accountName)
+	_, found := repository.GetStorageAccountByName(accountName)
 	if !found {
 		http.Redirect(w, r, "/storage-accounts", http.StatusSeeOther)
 		return
-	} // Set environment variables for Azure storage
-	os.Setenv("AZURE_STORAGE_ACCOUNT_NAME", account.AccountName)
-	os.Setenv("AZURE_STORAGE_ACCOUNT_KEY", account.AccountKey)
-	os.Setenv("AZURE_STORAGE_CONTAINER", account.ContainerName) // Store the selected account in a cookie for better state management
+	}
+
+	// A conta fica apenas no cookie do usuário e é resolvida a cada requisição,
+	// para que a escolha de um usuário não afete os demais
 	selectedAccountCookie := &http.Cookie{
 		Name:     "selected_account",
 		Value:    accountName,
@@ -412,9 +430,6 @@ func SelectAccountHandler(w http.ResponseWriter, r *http.Request) {
 
 	http.SetCookie(w, selectedAccountCookie)
 
-	// Clear Azure client cache to use new credentials
-	azure.ResetClient()
-
 	// Redirect to home page to browse files
 	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
@@ -574,6 +589,27 @@ func getSessionUser(r *http.Request) (string, bool) {
 	return cookie.Value, true
 }
 
+// canWriteFiles verifica se o usuário da requisição pode alterar arquivos:
+// administradores (locais ou via OIDC) e usuários com permissão de escrita
+func canWriteFiles(r *http.Request) bool {
+	if r.Header.Get("X-User-Is-Admin") == "true" {
+		return true
+	}
+
+	username, authenticated := getSessionUser(r)
+	return authenticated && repository.CanUserWrite(username)
+}
+
+// isAdminRequest verifica se o usuário da requisição é administrador (local ou via OIDC)
+func isAdminRequest(r *http.Request) bool {
+	if r.Header.Get("X-User-Is-Admin") == "true" {
+		return true
+	}
+
+	username, authenticated := getSessionUser(r)
+	return authenticated && repository.IsUserAdmin(username)
+}
+
 func clearSession(w http.ResponseWriter) {
 	cookie := &http.Cookie{
 		Name:     "session_user",
@@ -698,6 +734,9 @@ func AccessDeniedHandler(w http.ResponseWriter, r *http.Request, message string)
 // Middleware to check if user is authenticated
 func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		// O header de administrador só pode ser definido por este middleware
+		r.Header.Del("X-User-Is-Admin")
+
 		// Exclude login page, token storage and access-denied from authentication check
 		if r.URL.Path == "/login" || r.URL.Path == "/auth/store-token" || r.URL.Path == "/access-denied" {
 			next(w, r)
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fileblobs/internal/repository"
+	"fileblobs/pkg/storage"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// copySASExpiry é a validade da SAS de leitura entregue ao Azure. A cópia é
+// assíncrona e pode levar horas entre regiões, então a margem é generosa.
+const copySASExpiry = 24 * time.Hour
+
+// CopyToAccountHandler copia arquivos ("paths") e pastas ("folders") para a pasta
+// "destination" de outra conta ("destinationAccount"), opcionalmente em outro
+// container ("destinationContainer") já cadastrado ou, para administradores,
+// qualquer container da conta. Entre contas do Azure a cópia é feita pelo
+// próprio Azure (StartCopyFromURL com uma SAS de leitura da origem), sem que os
+// bytes passem pelo fileblobs. O progresso é consultado em /job-status.
+func (h *FileHandlers) CopyToAccountHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, r, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !canWriteFiles(r) {
+		respondWithError(w, r, "Acesso negado. Você não tem permissão para copiar arquivos.", http.StatusForbidden)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		respondWithError(w, r, "Erro ao ler formulário", http.StatusBadRequest)
+		return
+	}
+
+	files := r.Form["paths"]
+	folders := r.Form["folders"]
+	destAccount := r.FormValue("destinationAccount")
+	destContainer := strings.TrimSpace(r.FormValue("destinationContainer"))
+	overwrite := r.FormValue("overwrite") == "1" || r.FormValue("overwrite") == "true"
+
+	if len(files) == 0 && len(folders) == 0 {
+		respondWithError(w, r, "Nenhum arquivo selecionado", http.StatusBadRequest)
+		return
+	}
+	account, found := repository.GetStorageAccountByName(destAccount)
+	if !found {
+		respondWithError(w, r, "Conta de destino não encontrada", http.StatusBadRequest)
+		return
+	}
+
+	srcStore, ok := h.store(w, r)
+	if !ok {
+		return
+	}
+	dstStore, dstName, ok := h.destinationStore(w, r, account, destContainer)
+	if !ok {
+		return
+	}
+
+	srcAccount := accountNameFromRequest(r)
+	if srcAccount == "" {
+		srcAccount = repository.DefaultAccountName
+	}
+	sameStore := srcAccount == dstName
+
+	destination := strings.Trim(storage.CleanPath(r.FormValue("destination")), "/")
+	pairs, err := planTransfer(r.Context(), srcStore, files, folders, destination, "", sameStore)
+	if err != nil {
+		respondWithError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	owner, _ := getSessionUser(r)
+	job, err := startJob(owner, pairs, func(ctx context.Context, pair transferPair) error {
+		return copyBetweenStores(ctx, srcStore, dstStore, pair, overwrite)
+	}, copyErrorMessage)
+	if err != nil {
+		respondWithError(w, r, "Erro ao iniciar cópia", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Usuário %s iniciou a cópia de %d arquivo(s) de %s para %s/%s", owner, len(pairs), srcAccount, destAccount, destination)
+	respondWithJob(w, job)
+}
+
+// destinationStore abre o armazenamento de destino de uma cópia entre contas e
+// retorna também o nome da conta cadastrada que o atende. Um container diferente
+// do configurado na conta só é aceito quando outra conta cadastrada, com as
+// mesmas credenciais, já aponta para ele; fora disso, apenas administradores
+// podem escolher o container, e o nome retornado fica vazio.
+func (h *FileHandlers) destinationStore(w http.ResponseWriter, r *http.Request, account repository.StorageAccount, container string) (storage.BlobStore, string, bool) {
+	if container != "" && container != accountContainer(account) {
+		if configured, found := accountForContainer(account, container); found {
+			account = configured
+		} else {
+			return h.unlistedContainerStore(w, r, account, container)
+		}
+	}
+
+	store, ok := h.store(w, requestForAccount(r, account.Name))
+	return store, account.Name, ok
+}
+
+// unlistedContainerStore abre, para administradores, um container da conta que
+// não está cadastrado como conta própria
+func (h *FileHandlers) unlistedContainerStore(w http.ResponseWriter, r *http.Request, account repository.StorageAccount, container string) (storage.BlobStore, string, bool) {
+	if !isAdminRequest(r) {
+		respondWithError(w, r, "Acesso negado. Somente administradores podem copiar para um container que não está cadastrado.", http.StatusForbidden)
+		return nil, "", false
+	}
+
+	cfg := account.StorageConfig()
+	switch cfg.Type {
+	case storage.TypeAzure:
+		cfg.ContainerName = container
+	case storage.TypeS3:
+		cfg.Bucket = container
+	default:
+		respondWithError(w, r, "O armazenamento da conta de destino não tem containers", http.StatusBadRequest)
+		return nil, "", false
+	}
+
+	store, err := storage.Open(cfg)
+	if err != nil {
+		log.Printf("Erro ao abrir o container %s da conta %s: %v", container, account.Name, err)
+		respondWithError(w, r, "Conta de armazenamento indisponível", http.StatusServiceUnavailable)
+		return nil, "", false
+	}
+	return store, "", true
+}
+
+// accountContainer retorna o container (ou bucket) configurado na conta
+func accountContainer(account repository.StorageAccount) string {
+	if account.StorageType() == storage.TypeS3 {
+		return account.Bucket
+	}
+	return account.ContainerName
+}
+
+// accountForContainer procura uma conta cadastrada com as mesmas credenciais
+// de "account" que aponte para o container informado
+func accountForContainer(account repository.StorageAccount, container string) (repository.StorageAccount, bool) {
+	for _, candidate := range repository.GetStorageAccounts() {
+		if candidate.StorageType() != account.StorageType() || accountContainer(candidate) != container {
+			continue
+		}
+		switch account.StorageType() {
+		case storage.TypeAzure:
+			if candidate.AccountName == account.AccountName {
+				return candidate, true
+			}
+		case storage.TypeS3:
+			if candidate.Endpoint == account.Endpoint && candidate.AccessKeyID == account.AccessKeyID {
+				return candidate, true
+			}
+		}
+	}
+	return repository.StorageAccount{}, false
+}
+
+// copyBetweenStores copia um arquivo entre armazenamentos e confere o resultado.
+// Quando a origem gera URLs assinadas e o destino copia a partir de URLs, a cópia
+// acontece no servidor de armazenamento; nos demais casos o conteúdo passa pelo
+// fileblobs em stream.
+func copyBetweenStores(ctx context.Context, src, dst storage.BlobStore, pair transferPair, overwrite bool) error {
+	srcInfo, err := src.Stat(ctx, pair.Src)
+	if err != nil {
+		return err
+	}
+
+	if err := checkDestination(ctx, dst, pair.Dst, overwrite); err != nil {
+		return err
+	}
+
+	signer, canSign := src.(storage.URLSigner)
+	copier, canCopy := dst.(storage.RemoteCopier)
+
+	if canSign && canCopy {
+		url, err := signer.SignedURL(ctx, pair.Src, copySASExpiry)
+		if err != nil {
+			return err
+		}
+		if err := copier.CopyFromURL(ctx, url, pair.Dst); err != nil {
+			return err
+		}
+	} else {
+		body, _, err := src.Open(ctx, pair.Src)
+		if err != nil {
+			return err
+		}
+		err = dst.Put(ctx, pair.Dst, body)
+		body.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return verifyCopy(ctx, src, dst, pair.Dst, srcInfo)
+}
+
+func copyErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return "Arquivo não encontrado"
+	case errors.Is(err, errDestinationExists):
+		return "Já existe um arquivo com este nome no destino"
+	case errors.Is(err, errCopyMismatch):
+		return "A cópia não confere com a origem"
+	}
+	return "Erro ao copiar arquivo"
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fileblobs/pkg/storage"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// deleteWorkers limita quantas exclusões são feitas em paralelo
+const deleteWorkers = 8
+
+// deletePreviewLimit limita quantos arquivos são listados na confirmação
+const deletePreviewLimit = 1000
+
+// errDeleteRoot impede que uma pasta vazia no formulário apague o container inteiro
+var errDeleteRoot = errors.New("não é permitido excluir a raiz do armazenamento")
+
+// DeleteResult é o resultado da exclusão de um arquivo
+type DeleteResult struct {
+	Path    string `json:"path"`
+	Deleted bool   `json:"deleted"`
+	Error   string `json:"error,omitempty"`
+}
+
+// DeleteResponse é a resposta de /delete. Sem confirmação, Items lista o que
+// seria excluído; com confirmação, Results traz o resultado de cada arquivo.
+type DeleteResponse struct {
+	Confirmed bool           `json:"confirmed"`
+	Total     int            `json:"total"`
+	Items     []string       `json:"items,omitempty"`
+	Truncated bool           `json:"truncated,omitempty"`
+	Results   []DeleteResult `json:"results,omitempty"`
+	Deleted   int            `json:"deleted"`
+	Failed    int            `json:"failed"`
+}
+
+// DeleteHandler exclui arquivos ("paths") e pastas inteiras ("folders").
+// A exclusão acontece em duas etapas: a primeira chamada apenas lista os
+// arquivos afetados, e só a chamada com confirm=1 os remove de fato.
+func (h *FileHandlers) DeleteHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, r, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !canWriteFiles(r) {
+		respondWithError(w, r, "Acesso negado. Você não tem permissão para excluir arquivos.", http.StatusForbidden)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		respondWithError(w, r, "Erro ao ler formulário", http.StatusBadRequest)
+		return
+	}
+
+	files := r.Form["paths"]
+	folders := r.Form["folders"]
+	if len(files) == 0 && len(folders) == 0 {
+		respondWithError(w, r, "Nenhum arquivo selecionado", http.StatusBadRequest)
+		return
+	}
+
+	store, ok := h.store(w, r)
+	if !ok {
+		return
+	}
+
+	targets, err := expandDeleteTargets(r.Context(), store, files, folders)
+	if err != nil {
+		if errors.Is(err, errDeleteRoot) {
+			respondWithError(w, r, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Erro ao listar arquivos para exclusão: %v", err)
+		respondWithError(w, r, "Erro ao listar arquivos para exclusão", http.StatusInternalServerError)
+		return
+	}
+
+	response := DeleteResponse{Total: len(targets)}
+
+	confirm := r.FormValue("confirm")
+	if confirm != "1" && confirm != "true" {
+		response.Items = targets
+		if len(targets) > deletePreviewLimit {
+			response.Items = targets[:deletePreviewLimit]
+			response.Truncated = true
+		}
+		writeDeleteResponse(w, response)
+		return
+	}
+
+	response.Confirmed = true
+	response.Results = deleteFiles(r.Context(), store, targets)
+	for _, result := range response.Results {
+		if result.Deleted {
+			response.Deleted++
+		} else {
+			response.Failed++
+		}
+	}
+
+	username, _ := getSessionUser(r)
+	log.Printf("Usuário %s excluiu %d arquivo(s), %d falha(s)", username, response.Deleted, response.Failed)
+
+	writeDeleteResponse(w, response)
+}
+
+// expandDeleteTargets transforma arquivos e pastas na lista ordenada e sem
+// repetições dos arquivos a excluir; as pastas são percorridas recursivamente
+func expandDeleteTargets(ctx context.Context, store storage.BlobStore, files, folders []string) ([]string, error) {
+	seen := make(map[string]bool)
+
+	for _, file := range files {
+		file = storage.CleanPath(file)
+		if file != "" {
+			seen[file] = true
+		}
+	}
+
+	for _, folder := range folders {
+		prefix := strings.TrimSuffix(storage.CleanPath(folder), "/")
+		if prefix == "" {
+			return nil, errDeleteRoot
+		}
+
+		listing, err := store.List(ctx, prefix+"/", storage.ListOptions{Recursive: true})
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range listing.Files {
+			seen[file.Name] = true
+		}
+	}
+
+	targets := make([]string, 0, len(seen))
+	for path := range seen {
+		targets = append(targets, path)
+	}
+	sort.Strings(targets)
+	return targets, nil
+}
+
+// deleteFiles exclui os arquivos em paralelo, mantendo os resultados na ordem recebida
+func deleteFiles(ctx context.Context, store storage.BlobStore, targets []string) []DeleteResult {
+	results := make([]DeleteResult, len(targets))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(deleteWorkers, len(targets)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = DeleteResult{Path: targets[i]}
+				if err := store.Delete(ctx, targets[i]); err != nil {
+					log.Printf("Erro ao excluir %s: %v", targets[i], err)
+					results[i].Error = deleteErrorMessage(err)
+					continue
+				}
+				results[i].Deleted = true
+			}
+		}()
+	}
+
+	for i := range targets {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
+
+func deleteErrorMessage(err error) string {
+	if errors.Is(err, storage.ErrNotFound) {
+		return "Arquivo não encontrado"
+	}
+	return "Erro ao excluir arquivo"
+}
+
+func writeDeleteResponse(w http.ResponseWriter, response DeleteResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
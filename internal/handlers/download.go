@@ -2,30 +2,126 @@ package handlers
 
 import (
 	"encoding/json"
-	"fileblobs/pkg/azure"
+	"errors"
+	"fileblobs/pkg/storage"
+	"fmt"
 	"log"
 	"net/http"
 	"strings"
 )
 
-func DownloadHandler(w http.ResponseWriter, r *http.Request) {
+// DownloadHandler envia o arquivo diretamente do armazenamento para o cliente,
+// sem carregá-lo em memória. Suporta requisições HEAD, Range (uma ou várias
+// faixas, respondidas com 206) e GET condicional por ETag/If-None-Match e
+// If-Modified-Since (respondido com 304), permitindo retomar downloads.
+func (h *FileHandlers) DownloadHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		respondWithError(w, r, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
 	blobPath := r.URL.Query().Get("path")
 	if blobPath == "" {
 		respondWithError(w, r, "Caminho do arquivo não especificado", http.StatusBadRequest)
 		return
 	}
 
-	data, err := azure.DownloadBlob(blobPath)
+	store, ok := h.store(w, r)
+	if !ok {
+		return
+	}
+
+	info, err := store.Stat(r.Context(), blobPath)
 	if err != nil {
-		log.Printf("Erro ao baixar arquivo %s: %v", blobPath, err)
-		respondWithError(w, r, "Erro ao baixar arquivo", http.StatusInternalServerError)
+		respondWithStorageError(w, r, blobPath, err)
+		return
+	}
+	if rejectArchived(w, r, info) {
+		return
+	}
+
+	// O conteúdo só é lido sob demanda, por faixas, conforme o ServeContent precisar.
+	// Respostas 304 e HEAD não chegam a baixar nada do armazenamento.
+	content := storage.NewRangeReader(r.Context(), store, info, r.Header.Get("Range"))
+	defer content.Close()
+
+	setDownloadHeaders(w, info)
+	http.ServeContent(w, r, info.Name, info.LastModified, content)
+}
+
+// setDownloadHeaders define os cabeçalhos da resposta a partir das propriedades do arquivo.
+// Content-Length e Last-Modified são definidos pelo http.ServeContent.
+func setDownloadHeaders(w http.ResponseWriter, info storage.FileInfo) {
+	contentType := info.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	w.Header().Set("Content-Disposition", contentDisposition("attachment", baseName(info.Name)))
+	w.Header().Set("Content-Type", contentType)
+	if info.ETag != "" {
+		w.Header().Set("ETag", info.ETag)
+	}
+}
+
+// contentDisposition monta o cabeçalho Content-Disposition conforme a RFC 6266:
+// filename traz uma versão ASCII do nome para clientes antigos, e filename*
+// traz o nome original em UTF-8, preservando acentos como em "relatório.pdf"
+func contentDisposition(disposition, name string) string {
+	var fallback, encoded strings.Builder
+	for _, r := range name {
+		switch {
+		case r < 0x20 || r == 0x7f:
+			// Caracteres de controle não podem aparecer no cabeçalho
+			continue
+		case r > 0x7e:
+			fallback.WriteByte('_')
+		case r == '"' || r == '\\':
+			fallback.WriteByte('\\')
+			fallback.WriteRune(r)
+		default:
+			fallback.WriteRune(r)
+		}
+
+		if isAttrChar(r) {
+			encoded.WriteRune(r)
+			continue
+		}
+		for _, b := range []byte(string(r)) {
+			fmt.Fprintf(&encoded, "%%%02X", b)
+		}
+	}
+
+	return fmt.Sprintf(`%s; filename="%s"; filename*=UTF-8''%s`, disposition, fallback.String(), encoded.String())
+}
+
+// isAttrChar indica os caracteres que a RFC 8187 permite sem codificação em filename*
+func isAttrChar(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return true
+	}
+	return strings.ContainsRune("!#$&+-.^_`|~", r)
+}
+
+// respondWithStorageError traduz um erro do armazenamento na resposta adequada
+func respondWithStorageError(w http.ResponseWriter, r *http.Request, blobPath string, err error) {
+	if errors.Is(err, storage.ErrNotFound) {
+		respondWithError(w, r, "Arquivo não encontrado", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, storage.ErrArchived) {
+		respondWithError(w, r, archivedMessage(storage.FileInfo{AccessTier: storage.TierArchive}), http.StatusConflict)
+		return
+	}
+	if errors.Is(err, storage.ErrRehydrating) {
+		respondWithError(w, r, rehydratingMessage, http.StatusConflict)
 		return
 	}
 
-	w.Header().Set("Content-Disposition", "attachment; filename="+baseName(blobPath))
-	w.Header().Set("Content-Type", "application/octet-stream")
-	w.WriteHeader(http.StatusOK)
-	w.Write(data)
+	log.Printf("Erro ao baixar arquivo %s: %v", blobPath, err)
+	respondWithError(w, r, "Erro ao baixar arquivo", http.StatusInternalServerError)
 }
 
 // respondWithError returns an appropriate error response based on the Accept header
@@ -2,15 +2,14 @@ package handlers
 
 import (
 	"archive/zip"
-	"bytes"
-	"fileblobs/pkg/azure"
+	"fileblobs/pkg/storage"
 	"io"
 	"log"
 	"net/http"
 	"strings"
 )
 
-func DownloadFolderHandler(w http.ResponseWriter, r *http.Request) {
+func (h *FileHandlers) DownloadFolderHandler(w http.ResponseWriter, r *http.Request) {
 	prefix := r.URL.Query().Get("path")
 	if prefix == "" {
 		respondWithError(w, r, "Caminho não informado", http.StatusBadRequest)
@@ -21,42 +20,54 @@ func DownloadFolderHandler(w http.ResponseWriter, r *http.Request) {
 		prefix += "/"
 	}
 
-	files, err := azure.ListBlobsFromFolder(prefix)
+	store, ok := h.store(w, r)
+	if !ok {
+		return
+	}
+
+	listing, err := store.List(r.Context(), prefix, storage.ListOptions{Recursive: true})
 	if err != nil {
 		log.Printf("Erro ao listar arquivos da pasta %s: %v", prefix, err)
 		respondWithError(w, r, "Erro ao listar arquivos", http.StatusInternalServerError)
 		return
 	}
 
-	if len(files) == 0 {
+	if len(listing.Files) == 0 {
 		respondWithError(w, r, "Pasta vazia ou não encontrada", http.StatusNotFound)
 		return
 	}
 
+	if rejectArchivedFiles(w, r, listing.Files) {
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/zip")
 	w.Header().Set("Content-Disposition", "attachment; filename=pasta.zip")
 
 	zipWriter := zip.NewWriter(w)
 	defer zipWriter.Close()
 
-	for _, path := range files {
-		data, err := azure.DownloadBlob(path)
-		if err != nil {
-			log.Printf("Erro ao baixar arquivo %s: %v", path, err)
+	prefix = storage.CleanPath(prefix)
+	for _, file := range listing.Files {
+		relative := strings.TrimPrefix(file.Name, prefix)
+		if relative == "" {
 			continue
 		}
 
-		relative := strings.TrimPrefix(path, prefix)
-		if relative == "" {
+		body, _, err := store.Open(r.Context(), file.Name)
+		if err != nil {
+			log.Printf("Erro ao baixar arquivo %s: %v", file.Name, err)
 			continue
 		}
 
 		fw, err := zipWriter.Create(relative)
 		if err != nil {
 			log.Printf("Erro ao criar entrada no ZIP para %s: %v", relative, err)
+			body.Close()
 			continue
 		}
 
-		io.Copy(fw, bytes.NewReader(data))
+		io.Copy(fw, body)
+		body.Close()
 	}
 }
@@ -2,14 +2,13 @@ package handlers
 
 import (
 	"archive/zip"
-	"bytes"
-	"fileblobs/pkg/azure"
+	"fileblobs/pkg/storage"
 	"io"
 	"log"
 	"net/http"
 )
 
-func DownloadMultipleHandler(w http.ResponseWriter, r *http.Request) {
+func (h *FileHandlers) DownloadMultipleHandler(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
 		respondWithError(w, r, "Erro ao processar formulário", http.StatusBadRequest)
 		return
@@ -23,6 +22,26 @@ func DownloadMultipleHandler(w http.ResponseWriter, r *http.Request) {
 
 	prefix := r.FormValue("prefix")
 
+	store, ok := h.store(w, r)
+	if !ok {
+		return
+	}
+
+	// Os arquivos são conferidos antes do cabeçalho do ZIP, para que um arquivo
+	// inexistente ou na camada Archive gere um erro em vez de faltar no ZIP
+	infos := make([]storage.FileInfo, 0, len(files))
+	for _, path := range files {
+		info, err := store.Stat(r.Context(), path)
+		if err != nil {
+			respondWithStorageError(w, r, path, err)
+			return
+		}
+		infos = append(infos, info)
+	}
+	if rejectArchivedFiles(w, r, infos) {
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/zip")
 	w.Header().Set("Content-Disposition", "attachment; filename=arquivos.zip")
 
@@ -32,7 +51,7 @@ func DownloadMultipleHandler(w http.ResponseWriter, r *http.Request) {
 	successCount := 0
 
 	for _, path := range files {
-		data, err := azure.DownloadBlob(path)
+		body, _, err := store.Open(r.Context(), path)
 		if err != nil {
 			log.Printf("Erro ao baixar arquivo %s: %v", path, err)
 			continue
@@ -46,10 +65,12 @@ func DownloadMultipleHandler(w http.ResponseWriter, r *http.Request) {
 		fw, err := zipWriter.Create(relativePath)
 		if err != nil {
 			log.Printf("Erro ao criar entrada no ZIP para %s: %v", relativePath, err)
+			body.Close()
 			continue
 		}
 
-		io.Copy(fw, bytes.NewReader(data))
+		io.Copy(fw, body)
+		body.Close()
 		successCount++
 	}
 
@@ -0,0 +1,69 @@
+package handlers
+
+import "testing"
+
+func TestContentDisposition(t *testing.T) {
+	tests := []struct {
+		name        string
+		disposition string
+		file        string
+		want        string
+	}{
+		{
+			name:        "ASCII simples",
+			disposition: "attachment",
+			file:        "relatorio-2024.pdf",
+			want:        `attachment; filename="relatorio-2024.pdf"; filename*=UTF-8''relatorio-2024.pdf`,
+		},
+		{
+			name:        "espaços e parênteses",
+			disposition: "inline",
+			file:        "nota (1).txt",
+			want:        `inline; filename="nota (1).txt"; filename*=UTF-8''nota%20%281%29.txt`,
+		},
+		{
+			name:        "aspas e barra invertida",
+			disposition: "attachment",
+			file:        `a"b\c.txt`,
+			want:        `attachment; filename="a\"b\\c.txt"; filename*=UTF-8''a%22b%5Cc.txt`,
+		},
+		{
+			name:        "não ASCII",
+			disposition: "attachment",
+			file:        "ação €.txt",
+			want:        `attachment; filename="a__o _.txt"; filename*=UTF-8''a%C3%A7%C3%A3o%20%E2%82%AC.txt`,
+		},
+		{
+			name:        "caracteres de controle",
+			disposition: "attachment",
+			file:        "a\r\nb\tc\x00\x7f.txt",
+			want:        `attachment; filename="abc.txt"; filename*=UTF-8''abc.txt`,
+		},
+		{
+			name:        "separadores da RFC 8187",
+			disposition: "attachment",
+			file:        "a;b=c,d.txt",
+			want:        `attachment; filename="a;b=c,d.txt"; filename*=UTF-8''a%3Bb%3Dc%2Cd.txt`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := contentDisposition(tt.disposition, tt.file); got != tt.want {
+				t.Errorf("contentDisposition(%q, %q) =\n%s\nesperado\n%s", tt.disposition, tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsAttrChar(t *testing.T) {
+	for _, r := range "azAZ09!#$&+-.^_`|~" {
+		if !isAttrChar(r) {
+			t.Errorf("isAttrChar(%q) = false, esperado true", r)
+		}
+	}
+	for _, r := range " \"%'()*,/:;<=>?@[\\]{}\x00\x1f\x7fçé€😀" {
+		if isAttrChar(r) {
+			t.Errorf("isAttrChar(%q) = true, esperado false", r)
+		}
+	}
+}
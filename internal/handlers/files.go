@@ -1,23 +1,67 @@
 package handlers
 
 import (
-	"fileblobs/pkg/azure"
+	"encoding/base64"
+	"fileblobs/internal/repository"
+	"fileblobs/pkg/storage"
+	"fmt"
 	"html/template"
 	"log"
 	"net/http"
+	"net/url"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type PageData struct {
 	Folders          []string
-	Files            []string
+	Files            []storage.FileInfo
 	Prefix           string
 	Query            string
 	DownloadMode     bool
 	IsDefaultAccount bool
+	CanWrite         bool     // Exibe as ações que alteram arquivos
+	Accounts         []string // Contas disponíveis como destino de cópias
+	CurrentAccount   string
+	Versioning       bool     // O armazenamento guarda versões dos arquivos
+	RecycleBin       bool     // O armazenamento mantém os arquivos excluídos
+	Tiers            []string // Camadas disponíveis; vazio se o armazenamento não tiver camadas
+	Tagging          bool     // O armazenamento tem tags de índice
+
+	// Ordenação e paginação
+	Sort         string // "name", "size" ou "date"
+	Order        string // "asc" ou "desc"
+	SortURLs     map[string]string
+	PageSize     int
+	PageSizeURLs []PageSizeLink
+	Page         int
+	FirstURL     string
+	PrevURL      string
+	NextURL      string
+	SortPageOnly bool // A ordenação por tamanho ou data vale só para a página atual
+
+	// View é "list" (tabela) ou "grid" (miniaturas, para pastas com muitas imagens)
+	View        string
+	ListViewURL string
+	GridViewURL string
 }
 
+// PageSizeLink é uma opção de itens por página na listagem
+type PageSizeLink struct {
+	Size int
+	URL  string
+}
+
+// pageSizes são os tamanhos de página aceitos; o primeiro é o padrão
+var pageSizes = []int{200, 500, 1000, 5000}
+
+// maxPrevMarkers limita os marcadores de páginas anteriores guardados na URL,
+// na listagem e no visualizador; além deles, a navegação volta pela primeira página
+const maxPrevMarkers = 10
+
 var tmpl = template.Must(template.New("index.html").Funcs(template.FuncMap{
 	"splitPrefix": func(s string) []string {
 		s = strings.TrimSuffix(s, "/")
@@ -42,6 +86,26 @@ var tmpl = template.Must(template.New("index.html").Funcs(template.FuncMap{
 	"joinPrefix": func(parts []string, index int) string {
 		return strings.Join(parts[:index+1], "/")
 	},
+	"formatSize":   formatSize,
+	"canPreview":   canPreview,
+	"canView":      canView,
+	"hasThumbnail": hasThumbnail,
+	"isArchived":   storage.IsArchived,
+	// A camada de destino de uma reidratação em andamento
+	"rehydrateTarget": rehydrateTarget,
+	"formatTime": func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Local().Format("02/01/2006 15:04")
+	},
+	// O MD5 é exibido em base64, como no portal do Azure e no cabeçalho Content-MD5
+	"formatMD5": func(md5 []byte) string {
+		if len(md5) == 0 {
+			return ""
+		}
+		return base64.StdEncoding.EncodeToString(md5)
+	},
 	"fileIcon": func(filename string) string {
 		ext := strings.ToLower(filepath.Ext(filename))
 		switch ext {
@@ -63,19 +127,11 @@ var tmpl = template.Must(template.New("index.html").Funcs(template.FuncMap{
 			return "/static/icons/file.png"
 		}
 	},
-}).ParseFiles("web/templates/index.html"))
+}).ParseFiles(templateFile("index.html")))
 
-func ListFilesHandler(w http.ResponseWriter, r *http.Request) {
-	// Verificar o cookie selected_account logo no início
-	selectedAccountCookie, cookieErr := r.Cookie("selected_account")
-	selectedAccountName := ""
-
-	if cookieErr == nil {
-		selectedAccountName = selectedAccountCookie.Value
-		log.Printf("Cookie 'selected_account' encontrado com valor: '%s'", selectedAccountName)
-	} else {
-		log.Printf("Cookie 'selected_account' não encontrado: %v", cookieErr)
-	}
+func (h *FileHandlers) ListFilesHandler(w http.ResponseWriter, r *http.Request) {
+	// Conta selecionada na URL ou no cookie selected_account
+	selectedAccountName := accountNameFromRequest(r)
 
 	prefix := r.URL.Query().Get("prefix")
 	if prefix != "" && !strings.HasSuffix(prefix, "/") {
@@ -85,7 +141,28 @@ func ListFilesHandler(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query().Get("q")
 	downloadMode := r.URL.Query().Get("downloadMode") == "1"
 
-	folders, files, err := azure.ListFoldersAndFiles(prefix)
+	sortBy, order, pageSize := listingParams(r)
+	view := "list"
+	if r.URL.Query().Get("view") == "grid" {
+		view = "grid"
+	}
+	// A página atual começa em "marker"; "prev" guarda os marcadores das
+	// últimas páginas anteriores, já que o Azure só informa como seguir adiante
+	marker := r.URL.Query().Get("marker")
+	prev := r.URL.Query()["prev"]
+	if len(prev) > maxPrevMarkers {
+		prev = prev[len(prev)-maxPrevMarkers:]
+	}
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < len(prev)+1 {
+		page = len(prev) + 1
+	}
+
+	var listing storage.Listing
+	store, err := h.resolveStore(r)
+	if err == nil {
+		listing, err = listPage(r, store, prefix, marker, pageSize, query)
+	}
 	if err != nil {
 		log.Printf("Erro ao listar blobs: %v", err)
 
@@ -104,15 +181,15 @@ func ListFilesHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if query != "" {
-		folders = filterByQuery(folders, query)
-		files = filterByQuery(files, query)
-	}
+	folders := listing.Folders
+	files := listing.Files
+
+	sortListing(folders, files, sortBy, order == "desc")
 
 	// Verificar se é a conta padrão - verificando várias formas do nome para ser mais robusto
 	isDefaultAccount := selectedAccountName == "" ||
 		strings.Contains(strings.ToLower(selectedAccountName), "conta padr") ||
-		selectedAccountName == "Conta Padrão"
+		selectedAccountName == repository.DefaultAccountName
 
 	// Verificamos se estamos na raiz da conta padrão
 	isRootOfDefaultAccount := isDefaultAccount && prefix == ""
@@ -129,6 +206,27 @@ func ListFilesHandler(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
+	_, versioning := store.(storage.Versioner)
+	_, recycleBin := store.(storage.RecycleBin)
+	_, tagging := store.(storage.Tagger)
+	var tiers []string
+	if _, ok := store.(storage.Tierer); ok {
+		tiers = storage.Tiers
+	}
+
+	canWrite := canWriteFiles(r)
+	var accounts []string
+	if canWrite {
+		for _, account := range repository.GetStorageAccounts() {
+			accounts = append(accounts, account.Name)
+		}
+	}
+
+	currentAccount := selectedAccountName
+	if currentAccount == "" {
+		currentAccount = repository.DefaultAccountName
+	}
+
 	data := PageData{
 		Folders:          folders,
 		Files:            files,
@@ -136,7 +234,188 @@ func ListFilesHandler(w http.ResponseWriter, r *http.Request) {
 		Query:            query,
 		DownloadMode:     downloadMode,
 		IsDefaultAccount: isRootOfDefaultAccount, // True quando estamos na raiz da conta padrão (onde queremos esconder botões)
+		CanWrite:         canWrite,
+		Accounts:         accounts,
+		CurrentAccount:   currentAccount,
+		Versioning:       versioning,
+		RecycleBin:       recycleBin,
+		Tiers:            tiers,
+		Tagging:          tagging,
+		Sort:             sortBy,
+		Order:            order,
+		SortURLs:         sortURLs(r, sortBy, order),
+		PageSize:         pageSize,
+		PageSizeURLs:     pageSizeURLs(r),
+		Page:             page,
+		SortPageOnly:     sortBy != "name" && (page > 1 || listing.NextMarker != ""),
+		View:             view,
+		ListViewURL:      listURL(r, url.Values{"view": nil}),
+		GridViewURL:      listURL(r, url.Values{"view": {"grid"}}),
+	}
+	if page > 1 {
+		data.FirstURL = listURL(r, url.Values{"marker": nil, "prev": nil, "page": nil})
+	}
+	if len(prev) > 0 {
+		data.PrevURL = listURL(r, url.Values{
+			"marker": {prev[len(prev)-1]},
+			"prev":   prev[:len(prev)-1],
+			"page":   {strconv.Itoa(page - 1)},
+		})
+	}
+	if listing.NextMarker != "" {
+		next := append(prev, marker)
+		if len(next) > maxPrevMarkers {
+			next = next[len(next)-maxPrevMarkers:]
+		}
+		data.NextURL = listURL(r, url.Values{
+			"marker": {listing.NextMarker},
+			"prev":   next,
+			"page":   {strconv.Itoa(page + 1)},
+		})
 	}
 
 	tmpl.Execute(w, data)
 }
+
+// listPage lista uma página da pasta a partir de marker. Com uma busca "query",
+// as páginas do armazenamento são percorridas até reunir pageSize itens que
+// casam com ela, para que o filtro valha para a pasta inteira e não só para a
+// página; a página filtrada pode então passar um pouco de pageSize.
+func listPage(r *http.Request, store storage.BlobStore, prefix, marker string, pageSize int, query string) (storage.Listing, error) {
+	opts := storage.ListOptions{MaxResults: pageSize, Marker: marker}
+	if query == "" {
+		return store.List(r.Context(), prefix, opts)
+	}
+
+	var page storage.Listing
+	for {
+		listing, err := store.List(r.Context(), prefix, opts)
+		if err != nil {
+			return storage.Listing{}, err
+		}
+		page.Folders = append(page.Folders, filterByQuery(listing.Folders, query)...)
+		page.Files = append(page.Files, filterFilesByQuery(listing.Files, query)...)
+		page.NextMarker = listing.NextMarker
+
+		if listing.NextMarker == "" || len(page.Folders)+len(page.Files) >= pageSize {
+			return page, nil
+		}
+		opts.Marker = listing.NextMarker
+	}
+}
+
+// listingParams lê a ordenação e o tamanho de página, aplicando os padrões
+func listingParams(r *http.Request) (sortBy, order string, pageSize int) {
+	sortBy = r.URL.Query().Get("sort")
+	if sortBy != "size" && sortBy != "date" {
+		sortBy = "name"
+	}
+
+	order = r.URL.Query().Get("order")
+	if order != "desc" {
+		order = "asc"
+	}
+
+	pageSize = pageSizes[0]
+	if n, err := strconv.Atoi(r.URL.Query().Get("pageSize")); err == nil {
+		for _, size := range pageSizes {
+			if n == size {
+				pageSize = n
+			}
+		}
+	}
+	return sortBy, order, pageSize
+}
+
+// sortListing ordena a página atual. Os backends listam sempre em ordem de
+// nome, então a ordenação por tamanho ou data vale dentro da página, o que a
+// listagem avisa quando a pasta tem mais de uma página.
+// As pastas não têm tamanho nem data e seguem sempre pelo nome.
+func sortListing(folders []string, files []storage.FileInfo, sortBy string, desc bool) {
+	sort.SliceStable(folders, func(i, j int) bool {
+		if desc && sortBy == "name" {
+			return folders[i] > folders[j]
+		}
+		return folders[i] < folders[j]
+	})
+
+	sort.SliceStable(files, func(i, j int) bool {
+		a, b := files[i], files[j]
+		if desc {
+			a, b = b, a
+		}
+		switch sortBy {
+		case "size":
+			if a.Size != b.Size {
+				return a.Size < b.Size
+			}
+		case "date":
+			if !a.LastModified.Equal(b.LastModified) {
+				return a.LastModified.Before(b.LastModified)
+			}
+		}
+		return a.Name < b.Name
+	})
+}
+
+// listURL monta o endereço da listagem mantendo os parâmetros atuais e
+// substituindo os informados em changes
+func listURL(r *http.Request, changes url.Values) string {
+	query := changeQuery(r, changes)
+	if query.Get("marker") == "" {
+		query.Del("marker")
+	}
+	return "/?" + query.Encode()
+}
+
+// changeQuery retorna os parâmetros da requisição com os de changes substituídos
+func changeQuery(r *http.Request, changes url.Values) url.Values {
+	query := r.URL.Query()
+	for key, values := range changes {
+		query.Del(key)
+		for _, value := range values {
+			query.Add(key, value)
+		}
+	}
+	return query
+}
+
+// sortURLs retorna, para cada coluna, o endereço que ordena por ela. Clicar na
+// coluna já ordenada inverte a ordem.
+func sortURLs(r *http.Request, sortBy, order string) map[string]string {
+	urls := make(map[string]string)
+	for _, column := range []string{"name", "size", "date"} {
+		next := "asc"
+		if column == sortBy && order == "asc" {
+			next = "desc"
+		}
+		urls[column] = listURL(r, url.Values{"sort": {column}, "order": {next}})
+	}
+	return urls
+}
+
+// pageSizeURLs retorna as opções de tamanho de página, voltando à primeira página
+func pageSizeURLs(r *http.Request) []PageSizeLink {
+	links := make([]PageSizeLink, 0, len(pageSizes))
+	for _, size := range pageSizes {
+		links = append(links, PageSizeLink{
+			Size: size,
+			URL:  listURL(r, url.Values{"pageSize": {strconv.Itoa(size)}, "marker": nil, "prev": nil, "page": nil}),
+		})
+	}
+	return links
+}
+
+// formatSize exibe um tamanho em bytes com a maior unidade adequada (KB, MB, ...)
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"bytes"
+	"fileblobs/pkg/storage"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// folderMarkerPath retorna o marcador que representa uma pasta vazia, conforme
+// FOLDER_MARKER: "slash" (padrão) grava um blob vazio "pasta/", e "keep"
+// grava um arquivo vazio "pasta/.keep"
+func folderMarkerPath(folder string) string {
+	folder = strings.TrimSuffix(folder, "/") + "/"
+	if strings.EqualFold(os.Getenv("FOLDER_MARKER"), "keep") {
+		return folder + storage.KeepFileName
+	}
+	return folder
+}
+
+// CreateFolderHandler cria uma pasta vazia ("name") dentro de "prefix". Como o
+// Azure e o S3 não têm diretórios, a pasta é um marcador que a listagem exibe
+// como pasta; no sistema de arquivos é um diretório de verdade.
+func (h *FileHandlers) CreateFolderHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, r, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !canWriteFiles(r) {
+		respondWithError(w, r, "Acesso negado. Você não tem permissão para criar pastas.", http.StatusForbidden)
+		return
+	}
+
+	name := strings.TrimSpace(r.FormValue("name"))
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, "/\\") {
+		respondWithError(w, r, "Nome de pasta inválido", http.StatusBadRequest)
+		return
+	}
+
+	store, ok := h.store(w, r)
+	if !ok {
+		return
+	}
+
+	prefix := normalizeUploadPrefix(storage.CleanPath(r.FormValue("prefix")))
+	folder := prefix + name + "/"
+
+	if err := store.Put(r.Context(), folderMarkerPath(folder), bytes.NewReader(nil)); err != nil {
+		log.Printf("Erro ao criar pasta %s: %v", folder, err)
+		respondWithError(w, r, "Erro ao criar pasta", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/?prefix="+url.QueryEscape(folder), http.StatusSeeOther)
+}
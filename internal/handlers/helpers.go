@@ -1,6 +1,16 @@
 package handlers
 
-import "strings"
+import (
+	"fileblobs/pkg/storage"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
 
 func filterByQuery(items []string, query string) []string {
 	var filtered []string
@@ -12,7 +22,82 @@ func filterByQuery(items []string, query string) []string {
 	return filtered
 }
 
+// filterFilesByQuery filtra os arquivos pelo nome, sem considerar a pasta
+func filterFilesByQuery(files []storage.FileInfo, query string) []storage.FileInfo {
+	var filtered []storage.FileInfo
+	for _, file := range files {
+		if strings.Contains(strings.ToLower(baseName(file.Name)), strings.ToLower(query)) {
+			filtered = append(filtered, file)
+		}
+	}
+	return filtered
+}
+
 func baseName(path string) string {
 	split := strings.Split(strings.TrimSuffix(path, "/"), "/")
 	return split[len(split)-1]
 }
+
+// folderURL é o endereço da pasta do arquivo no navegador de arquivos
+func folderURL(blobPath string) string {
+	folder := path.Dir(blobPath)
+	if folder == "." {
+		return "/"
+	}
+	return "/?prefix=" + url.QueryEscape(folder+"/")
+}
+
+// accountFolderURL é o endereço da pasta do arquivo, como folderURL, mantendo a
+// conta da requisição
+func accountFolderURL(r *http.Request, blobPath string) string {
+	params := url.Values{}
+	if folder := path.Dir(blobPath); folder != "." {
+		params.Set("prefix", folder+"/")
+	}
+	return accountURL(r, "/", params)
+}
+
+// accountURL monta um endereço com os parâmetros informados, mantendo a conta
+// ("account") da requisição quando presente
+func accountURL(r *http.Request, target string, params url.Values) string {
+	query := url.Values{}
+	if account := r.URL.Query().Get("account"); account != "" {
+		query.Set("account", account)
+	}
+	for key, values := range params {
+		query[key] = values
+	}
+
+	if len(query) == 0 {
+		return target
+	}
+	return target + "?" + query.Encode()
+}
+
+// capitalize deixa a primeira letra maiúscula, para exibir mensagens de erro ao usuário
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if size == 0 {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// templateFile retorna o caminho de um arquivo de web/templates. A pasta é
+// procurada a partir do diretório atual e subindo pelos pais, para que os
+// templates também sejam encontrados pelos testes, que rodam na pasta do pacote.
+func templateFile(name string) string {
+	dir, err := os.Getwd()
+	for err == nil {
+		candidate := filepath.Join(dir, "web", "templates", name)
+		if _, statErr := os.Stat(candidate); statErr == nil {
+			return candidate
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return filepath.Join("web", "templates", name)
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestAccountURL(t *testing.T) {
+	tests := []struct {
+		request string
+		params  url.Values
+		want    string
+	}{
+		{"/restore-version", nil, "/versions"},
+		{"/restore-version", url.Values{"path": {"a b.txt"}}, "/versions?path=a+b.txt"},
+		{
+			"/restore-version?account=Conta+Dois&path=x",
+			url.Values{"restored": {"1"}, "path": {"a/b.txt"}},
+			"/versions?account=Conta+Dois&path=a%2Fb.txt&restored=1",
+		},
+		{"/restore-version?account=", nil, "/versions"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("POST", tt.request, nil)
+		if got := accountURL(r, "/versions", tt.params); got != tt.want {
+			t.Errorf("accountURL(%q) = %q, esperado %q", tt.request, got, tt.want)
+		}
+	}
+}
+
+func TestAccountFolderURL(t *testing.T) {
+	tests := []struct {
+		request string
+		path    string
+		want    string
+	}{
+		{"/tag-search?where=x", "nota.txt", "/"},
+		{"/tag-search?where=x", "a/b/nota.txt", "/?prefix=a%2Fb%2F"},
+		{"/tag-search?account=Dois&where=x", "nota.txt", "/?account=Dois"},
+		{"/tag-search?account=Dois&where=x", "a/nota.txt", "/?account=Dois&prefix=a%2F"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", tt.request, nil)
+		if got := accountFolderURL(r, tt.path); got != tt.want {
+			t.Errorf("accountFolderURL(%q, %q) = %q, esperado %q", tt.request, tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// jobWorkers limita quantos arquivos são processados em paralelo por tarefa
+const jobWorkers = 4
+
+// jobRetention é o tempo que o resultado de uma tarefa concluída fica disponível
+const jobRetention = time.Hour
+
+// transferPair é um arquivo de origem e o seu caminho de destino
+type transferPair struct {
+	Src string
+	Dst string
+}
+
+// JobError descreve um arquivo que não pôde ser processado
+type JobError struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// JobStatus é o progresso de uma tarefa em segundo plano (movimentação, cópia)
+type JobStatus struct {
+	ID        string     `json:"id"`
+	Status    string     `json:"status"` // "running" ou "done"
+	Total     int        `json:"total"`
+	Completed int        `json:"completed"`
+	Failed    int        `json:"failed"`
+	Errors    []JobError `json:"errors,omitempty"`
+}
+
+// job acompanha uma tarefa executada em segundo plano
+type job struct {
+	mu         sync.Mutex
+	owner      string
+	status     JobStatus
+	finishedAt time.Time
+}
+
+var (
+	jobs      = make(map[string]*job)
+	jobsMutex sync.Mutex
+)
+
+func (j *job) snapshot() JobStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	status := j.status
+	status.Errors = append([]JobError(nil), j.status.Errors...)
+	return status
+}
+
+func (j *job) record(pair transferPair, errMessage string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if errMessage != "" {
+		j.status.Failed++
+		j.status.Errors = append(j.status.Errors, JobError{Path: pair.Src, Error: errMessage})
+		return
+	}
+	j.status.Completed++
+}
+
+// startJob registra a tarefa e executa run para cada par em segundo plano,
+// desvinculada da requisição. errorMessage traduz os erros para o usuário.
+func startJob(owner string, pairs []transferPair, run func(context.Context, transferPair) error, errorMessage func(error) string) (*job, error) {
+	id, err := newRandomID()
+	if err != nil {
+		return nil, err
+	}
+
+	j := &job{
+		owner:  owner,
+		status: JobStatus{ID: id, Status: "running", Total: len(pairs)},
+	}
+
+	jobsMutex.Lock()
+	for jobID, old := range jobs {
+		old.mu.Lock()
+		expired := !old.finishedAt.IsZero() && time.Since(old.finishedAt) > jobRetention
+		old.mu.Unlock()
+		if expired {
+			delete(jobs, jobID)
+		}
+	}
+	jobs[id] = j
+	jobsMutex.Unlock()
+
+	go func() {
+		ctx := context.Background()
+		pending := make(chan transferPair)
+
+		var wg sync.WaitGroup
+		for range min(jobWorkers, len(pairs)) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for pair := range pending {
+					if err := run(ctx, pair); err != nil {
+						log.Printf("Erro ao processar %s para %s: %v", pair.Src, pair.Dst, err)
+						j.record(pair, errorMessage(err))
+						continue
+					}
+					j.record(pair, "")
+				}
+			}()
+		}
+
+		for _, pair := range pairs {
+			pending <- pair
+		}
+		close(pending)
+		wg.Wait()
+
+		j.mu.Lock()
+		j.status.Status = "done"
+		j.finishedAt = time.Now()
+		j.mu.Unlock()
+	}()
+
+	return j, nil
+}
+
+// respondWithJob responde ao início de uma tarefa com 202 e o seu status
+func respondWithJob(w http.ResponseWriter, j *job) {
+	status := j.snapshot()
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/job-status?id="+status.ID)
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(status)
+}
+
+// JobStatusHandler retorna o progresso de uma tarefa iniciada pelo usuário
+func JobStatusHandler(w http.ResponseWriter, r *http.Request) {
+	owner, _ := getSessionUser(r)
+	j, found := findJob(r.URL.Query().Get("id"), owner)
+	if !found {
+		respondWithError(w, r, "Tarefa não encontrada", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(j.snapshot())
+}
+
+// findJob retorna a tarefa se ela existir e tiver sido iniciada pelo usuário
+func findJob(id, owner string) (*job, bool) {
+	jobsMutex.Lock()
+	j, found := jobs[id]
+	jobsMutex.Unlock()
+
+	if !found || j.owner != owner {
+		return nil, false
+	}
+	return j, true
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fileblobs/pkg/storage"
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+)
+
+var (
+	errDestinationExists = errors.New("o destino já existe")
+	errCopyMismatch      = errors.New("a cópia não confere com a origem")
+)
+
+// MoveHandler move ou renomeia arquivos ("paths") e pastas ("folders") para a
+// pasta "destination". Com um único item, "name" define o novo nome. Cada
+// arquivo é copiado no servidor, a cópia é conferida e só então a origem é
+// excluída; assim, uma falha no meio do caminho nunca perde dados e basta
+// repetir a operação para mover o que restou. A movimentação roda em segundo
+// plano e o progresso é consultado em /job-status.
+func (h *FileHandlers) MoveHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, r, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !canWriteFiles(r) {
+		respondWithError(w, r, "Acesso negado. Você não tem permissão para mover arquivos.", http.StatusForbidden)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		respondWithError(w, r, "Erro ao ler formulário", http.StatusBadRequest)
+		return
+	}
+
+	files := r.Form["paths"]
+	folders := r.Form["folders"]
+	newName := strings.TrimSpace(r.FormValue("name"))
+	overwrite := r.FormValue("overwrite") == "1" || r.FormValue("overwrite") == "true"
+
+	if len(files) == 0 && len(folders) == 0 {
+		respondWithError(w, r, "Nenhum arquivo selecionado", http.StatusBadRequest)
+		return
+	}
+	if newName != "" && (len(files)+len(folders) != 1 || strings.ContainsAny(newName, "/\\")) {
+		respondWithError(w, r, "Para renomear, selecione um único item e informe um nome sem barras", http.StatusBadRequest)
+		return
+	}
+
+	store, ok := h.store(w, r)
+	if !ok {
+		return
+	}
+
+	destination := strings.Trim(storage.CleanPath(r.FormValue("destination")), "/")
+	pairs, err := planTransfer(r.Context(), store, files, folders, destination, newName, true)
+	if err != nil {
+		respondWithError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	owner, _ := getSessionUser(r)
+	job, err := startJob(owner, pairs, func(ctx context.Context, pair transferPair) error {
+		return moveFile(ctx, store, pair, overwrite)
+	}, moveErrorMessage)
+	if err != nil {
+		respondWithError(w, r, "Erro ao iniciar movimentação", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Usuário %s iniciou a movimentação de %d arquivo(s) para %q", owner, len(pairs), destination)
+	respondWithJob(w, job)
+}
+
+// planTransfer calcula o destino de cada arquivo. As pastas são expandidas
+// recursivamente, preservando a estrutura abaixo delas. sameStore indica que
+// origem e destino estão no mesmo armazenamento, onde um item não pode ir para
+// dentro de si mesmo.
+func planTransfer(ctx context.Context, store storage.BlobStore, files, folders []string, destination, newName string, sameStore bool) ([]transferPair, error) {
+	if destination != "" {
+		destination += "/"
+	}
+
+	var pairs []transferPair
+
+	for _, file := range files {
+		src := storage.CleanPath(file)
+		if src == "" {
+			continue
+		}
+		name := newName
+		if name == "" {
+			name = path.Base(src)
+		}
+		dst := destination + name
+		if sameStore && dst == src {
+			return nil, fmt.Errorf("%s já está no destino", src)
+		}
+		pairs = append(pairs, transferPair{Src: src, Dst: dst})
+	}
+
+	for _, folder := range folders {
+		srcPrefix := strings.Trim(storage.CleanPath(folder), "/")
+		if srcPrefix == "" {
+			return nil, errors.New("não é permitido selecionar a raiz do armazenamento")
+		}
+		srcPrefix += "/"
+
+		name := newName
+		if name == "" {
+			name = path.Base(strings.TrimSuffix(srcPrefix, "/"))
+		}
+		dstPrefix := destination + name + "/"
+		if sameStore && strings.HasPrefix(dstPrefix, srcPrefix) {
+			return nil, fmt.Errorf("o destino de %s não pode ficar dentro da própria pasta", strings.TrimSuffix(srcPrefix, "/"))
+		}
+
+		listing, err := store.List(ctx, srcPrefix, storage.ListOptions{Recursive: true})
+		if err != nil {
+			log.Printf("Erro ao listar %s: %v", srcPrefix, err)
+			return nil, fmt.Errorf("erro ao listar a pasta %s", strings.TrimSuffix(srcPrefix, "/"))
+		}
+		for _, file := range listing.Files {
+			pairs = append(pairs, transferPair{Src: file.Name, Dst: dstPrefix + strings.TrimPrefix(file.Name, srcPrefix)})
+		}
+	}
+
+	if len(pairs) == 0 {
+		return nil, errors.New("nenhum arquivo selecionado")
+	}
+	return pairs, nil
+}
+
+// moveFile copia um arquivo, confere a cópia e só então exclui a origem
+func moveFile(ctx context.Context, store storage.BlobStore, pair transferPair, overwrite bool) error {
+	srcInfo, err := store.Stat(ctx, pair.Src)
+	if err != nil {
+		return err
+	}
+
+	if err := checkDestination(ctx, store, pair.Dst, overwrite); err != nil {
+		return err
+	}
+
+	if err := store.Copy(ctx, pair.Src, pair.Dst); err != nil {
+		return err
+	}
+
+	if err := verifyCopy(ctx, store, store, pair.Dst, srcInfo); err != nil {
+		return err
+	}
+
+	return store.Delete(ctx, pair.Src)
+}
+
+// checkDestination recusa sobrescrever um arquivo existente, a menos que overwrite seja verdadeiro
+func checkDestination(ctx context.Context, store storage.BlobStore, dst string, overwrite bool) error {
+	if overwrite {
+		return nil
+	}
+
+	_, err := store.Stat(ctx, dst)
+	if err == nil {
+		return errDestinationExists
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// verifyCopy confere se a cópia tem o mesmo tamanho e o mesmo MD5 da origem.
+// Quando o backend não guarda o MD5 de um dos lados, ele é calculado lendo o
+// arquivo, já que o tamanho sozinho não prova a cópia.
+func verifyCopy(ctx context.Context, src, dst storage.BlobStore, dstPath string, srcInfo storage.FileInfo) error {
+	dstInfo, err := dst.Stat(ctx, dstPath)
+	if err != nil {
+		return err
+	}
+	if dstInfo.Size != srcInfo.Size {
+		return errCopyMismatch
+	}
+
+	srcMD5, err := storage.ContentMD5(ctx, src, srcInfo)
+	if err != nil {
+		return err
+	}
+	dstMD5, err := storage.ContentMD5(ctx, dst, dstInfo)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(srcMD5, dstMD5) {
+		return errCopyMismatch
+	}
+	return nil
+}
+
+func moveErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return "Arquivo não encontrado"
+	case errors.Is(err, errDestinationExists):
+		return "Já existe um arquivo com este nome no destino"
+	case errors.Is(err, errCopyMismatch):
+		return "A cópia não confere com a origem; a origem foi mantida"
+	}
+	return "Erro ao mover arquivo; a origem foi mantida"
+}
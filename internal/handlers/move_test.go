@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fileblobs/pkg/storage"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// memStore é um BlobStore em memória que, como um backend sem hash do
+// conteúdo, não informa Content-MD5 e usa ETags que não são um MD5
+type memStore struct {
+	mu      sync.Mutex
+	files   map[string][]byte
+	version int
+	etags   map[string]string
+
+	// corruptCopy troca o primeiro byte das cópias, mantendo o tamanho
+	corruptCopy bool
+}
+
+func newMemStore(files map[string]string) *memStore {
+	s := &memStore{files: make(map[string][]byte), etags: make(map[string]string)}
+	for name, content := range files {
+		s.put(name, []byte(content))
+	}
+	return s
+}
+
+func (s *memStore) put(name string, data []byte) {
+	s.version++
+	s.files[name] = data
+	s.etags[name] = fmt.Sprintf(`"v%d"`, s.version)
+}
+
+func (s *memStore) info(name string) (storage.FileInfo, error) {
+	data, ok := s.files[name]
+	if !ok {
+		return storage.FileInfo{}, fmt.Errorf("%s: %w", name, storage.ErrNotFound)
+	}
+	return storage.FileInfo{Name: name, Size: int64(len(data)), ETag: s.etags[name]}, nil
+}
+
+func (s *memStore) List(ctx context.Context, prefix string, opts storage.ListOptions) (storage.Listing, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var names []string
+	for name := range s.files {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var listing storage.Listing
+	for _, name := range names {
+		info, _ := s.info(name)
+		listing.Files = append(listing.Files, info)
+	}
+	return listing, nil
+}
+
+func (s *memStore) Stat(ctx context.Context, name string) (storage.FileInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.info(name)
+}
+
+func (s *memStore) Open(ctx context.Context, name string) (io.ReadCloser, storage.FileInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	info, err := s.info(name)
+	if err != nil {
+		return nil, storage.FileInfo{}, err
+	}
+	return io.NopCloser(bytes.NewReader(s.files[name])), info, nil
+}
+
+func (s *memStore) OpenRange(ctx context.Context, name string, offset, count int64) (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.files[name]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", name, storage.ErrNotFound)
+	}
+	data = data[min(offset, int64(len(data))):]
+	if count > 0 {
+		data = data[:min(count, int64(len(data)))]
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *memStore) Put(ctx context.Context, name string, body io.Reader) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.put(name, data)
+	return nil
+}
+
+func (s *memStore) Delete(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.files[name]; !ok {
+		return fmt.Errorf("%s: %w", name, storage.ErrNotFound)
+	}
+	delete(s.files, name)
+	delete(s.etags, name)
+	return nil
+}
+
+func (s *memStore) Copy(ctx context.Context, src, dst string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.files[src]
+	if !ok {
+		return fmt.Errorf("%s: %w", src, storage.ErrNotFound)
+	}
+	data = bytes.Clone(data)
+	if s.corruptCopy && len(data) > 0 {
+		data[0] ^= 0xff
+	}
+	s.put(dst, data)
+	return nil
+}
+
+func TestMoveFileWithoutStoredMD5(t *testing.T) {
+	ctx := context.Background()
+	store := newMemStore(map[string]string{"a/nota.txt": "conteúdo"})
+
+	if err := moveFile(ctx, store, transferPair{Src: "a/nota.txt", Dst: "b/nota.txt"}, false); err != nil {
+		t.Fatalf("moveFile: %v", err)
+	}
+	if _, err := store.Stat(ctx, "a/nota.txt"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("origem ainda existe após mover: %v", err)
+	}
+	if got := string(store.files["b/nota.txt"]); got != "conteúdo" {
+		t.Errorf("destino = %q, esperado %q", got, "conteúdo")
+	}
+}
+
+func TestMoveFileKeepsSourceOnMismatch(t *testing.T) {
+	ctx := context.Background()
+	store := newMemStore(map[string]string{"a/nota.txt": "conteúdo"})
+	store.corruptCopy = true
+
+	err := moveFile(ctx, store, transferPair{Src: "a/nota.txt", Dst: "b/nota.txt"}, false)
+	if !errors.Is(err, errCopyMismatch) {
+		t.Fatalf("moveFile = %v, esperado errCopyMismatch", err)
+	}
+	if _, err := store.Stat(ctx, "a/nota.txt"); err != nil {
+		t.Errorf("origem removida apesar da cópia diferente: %v", err)
+	}
+}
+
+func TestMoveFileEmpty(t *testing.T) {
+	// Marcadores de pasta são arquivos vazios, que também precisam ser movidos
+	ctx := context.Background()
+	store := newMemStore(map[string]string{"a/": ""})
+
+	if err := moveFile(ctx, store, transferPair{Src: "a/", Dst: "b/"}, false); err != nil {
+		t.Fatalf("moveFile: %v", err)
+	}
+	if _, ok := store.files["a/"]; ok {
+		t.Error("marcador de origem ainda existe após mover")
+	}
+}
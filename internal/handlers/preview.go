@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"fileblobs/pkg/storage"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// previewCSP impede que o conteúdo pré-visualizado execute scripts ou carregue
+// recursos externos. O sandbox isola até arquivos HTML e SVG enviados por
+// usuários, que são exibidos na mesma origem do fileblobs.
+const previewCSP = "default-src 'none'; img-src 'self' data:; media-src 'self'; style-src 'unsafe-inline'; frame-ancestors 'self'; sandbox"
+
+// pdfPreviewCSP libera o visualizador de PDF do navegador, que não funciona
+// com sandbox. Os scripts de um PDF rodam no visualizador, não na página.
+const pdfPreviewCSP = "default-src 'none'; object-src 'self'; frame-ancestors 'self'"
+
+// PreviewHandler exibe o arquivo no navegador em vez de baixá-lo. Aceita
+// imagens, PDF, texto, JSON, CSV, áudio e vídeo; outros tipos são recusados.
+// Como no download, o conteúdo é lido por faixas, o que permite avançar em
+// áudios e vídeos.
+func (h *FileHandlers) PreviewHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		respondWithError(w, r, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	blobPath := r.URL.Query().Get("path")
+	if blobPath == "" {
+		respondWithError(w, r, "Caminho do arquivo não especificado", http.StatusBadRequest)
+		return
+	}
+
+	store, ok := h.store(w, r)
+	if !ok {
+		return
+	}
+
+	info, err := store.Stat(r.Context(), blobPath)
+	if err != nil {
+		respondWithStorageError(w, r, blobPath, err)
+		return
+	}
+	if rejectArchived(w, r, info) {
+		return
+	}
+
+	contentType, ok := previewContentType(info)
+	if !ok {
+		respondWithError(w, r, "Pré-visualização não disponível para este tipo de arquivo", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	content := storage.NewRangeReader(r.Context(), store, info, r.Header.Get("Range"))
+	defer content.Close()
+
+	csp := previewCSP
+	if contentType == "application/pdf" {
+		csp = pdfPreviewCSP
+	}
+	w.Header().Set("Content-Security-Policy", csp)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("X-Frame-Options", "SAMEORIGIN")
+	w.Header().Set("Content-Disposition", contentDisposition("inline", baseName(info.Name)))
+	w.Header().Set("Content-Type", contentType)
+	if info.ETag != "" {
+		w.Header().Set("ETag", info.ETag)
+	}
+	http.ServeContent(w, r, info.Name, info.LastModified, content)
+}
+
+// canPreview indica se o arquivo pode ser aberto em /preview
+func canPreview(info storage.FileInfo) bool {
+	_, ok := previewContentType(info)
+	return ok
+}
+
+// previewContentType retorna o tipo com que o arquivo é exibido. Textos de
+// qualquer tipo, inclusive HTML, são exibidos como texto puro.
+func previewContentType(info storage.FileInfo) (string, bool) {
+	mediaType := storedMediaType(info)
+
+	switch {
+	case strings.HasPrefix(mediaType, "image/"),
+		strings.HasPrefix(mediaType, "audio/"),
+		strings.HasPrefix(mediaType, "video/"),
+		mediaType == "application/pdf":
+		return mediaType, true
+	case mediaType == "application/json":
+		return "application/json; charset=utf-8", true
+	case strings.HasPrefix(mediaType, "text/"):
+		return "text/plain; charset=utf-8", true
+	}
+	return "", false
+}
+
+// storedMediaType retorna o tipo MIME gravado com o arquivo, sem parâmetros.
+// Arquivos sem tipo gravado usam o tipo da extensão.
+func storedMediaType(info storage.FileInfo) string {
+	contentType := info.ContentType
+	if contentType == "" || strings.HasPrefix(contentType, "application/octet-stream") {
+		contentType = storage.DetectContentType(info.Name, nil)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return mediaType
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fileblobs/pkg/storage"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// recycleBinLimit limita quantos arquivos excluídos a lixeira exibe por vez;
+// acima disso, o usuário restringe a listagem a uma pasta
+const recycleBinLimit = 1000
+
+// RecycleBinData alimenta a página da lixeira
+type RecycleBinData struct {
+	Prefix    string
+	ParentURL string // Pasta acima do prefixo; vazio na raiz
+	Files     []storage.DeletedFile
+	Truncated bool
+	CanWrite  bool       // Exibe as ações de recuperar
+	Job       *JobStatus // Recuperação iniciada pelo usuário, com ?job=
+}
+
+var recycleBinTmpl = template.Must(template.New("recycle_bin.html").Funcs(template.FuncMap{
+	"formatSize":      formatSize,
+	"formatTimestamp": formatTimestamp,
+	"baseName":        baseName,
+	"relativePath": func(name, prefix string) string {
+		return strings.TrimPrefix(name, prefix)
+	},
+	"folderOf": func(name string) string {
+		if folder := path.Dir(name); folder != "." {
+			return folder + "/"
+		}
+		return ""
+	},
+}).ParseFiles(templateFile("recycle_bin.html")))
+
+// RecycleBinHandler lista os arquivos excluídos abaixo de "prefix" que ainda
+// podem ser recuperados, com a data da exclusão e os dias restantes de retenção
+func (h *FileHandlers) RecycleBinHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, r, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	bin, ok := h.recycleBin(w, r)
+	if !ok {
+		return
+	}
+
+	prefix := folderPrefix(r.URL.Query().Get("prefix"))
+
+	var files []storage.DeletedFile
+	truncated := false
+	err := bin.WalkDeleted(r.Context(), prefix, func(file storage.DeletedFile) error {
+		if len(files) == recycleBinLimit {
+			truncated = true
+			return storage.SkipAll
+		}
+		files = append(files, file)
+		return nil
+	})
+	if err != nil {
+		log.Printf("Erro ao listar a lixeira em %q: %v", prefix, err)
+		respondWithError(w, r, "Erro ao listar arquivos excluídos", http.StatusInternalServerError)
+		return
+	}
+
+	data := RecycleBinData{
+		Prefix:    prefix,
+		Files:     files,
+		Truncated: truncated,
+		CanWrite:  canWriteFiles(r),
+	}
+	if prefix != "" {
+		data.ParentURL = recycleBinURL(path.Dir(strings.TrimSuffix(prefix, "/")))
+	}
+	if id := r.URL.Query().Get("job"); id != "" {
+		owner, _ := getSessionUser(r)
+		if j, found := findJob(id, owner); found {
+			status := j.snapshot()
+			data.Job = &status
+		}
+	}
+
+	recycleBinTmpl.Execute(w, data)
+}
+
+// UndeleteHandler recupera arquivos ("paths") e pastas inteiras ("folders") da
+// lixeira. A recuperação roda em segundo plano: pedidos JSON recebem o status
+// da tarefa, e o formulário da lixeira volta para a página, que acompanha o
+// progresso.
+func (h *FileHandlers) UndeleteHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, r, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !canWriteFiles(r) {
+		respondWithError(w, r, "Acesso negado. Você não tem permissão para recuperar arquivos.", http.StatusForbidden)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		respondWithError(w, r, "Erro ao ler formulário", http.StatusBadRequest)
+		return
+	}
+
+	files := r.Form["paths"]
+	folders := r.Form["folders"]
+	if len(files) == 0 && len(folders) == 0 {
+		respondWithError(w, r, "Nenhum arquivo selecionado", http.StatusBadRequest)
+		return
+	}
+
+	bin, ok := h.recycleBin(w, r)
+	if !ok {
+		return
+	}
+
+	pairs, err := planUndelete(r.Context(), bin, files, folders)
+	if err != nil {
+		respondWithError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	owner, _ := getSessionUser(r)
+	job, err := startJob(owner, pairs, func(ctx context.Context, pair transferPair) error {
+		return bin.Undelete(ctx, pair.Src)
+	}, undeleteErrorMessage)
+	if err != nil {
+		respondWithError(w, r, "Erro ao iniciar recuperação", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Usuário %s iniciou a recuperação de %d arquivo(s)", owner, len(pairs))
+	if strings.Contains(r.Header.Get("Accept"), "application/json") {
+		respondWithJob(w, job)
+		return
+	}
+	http.Redirect(w, r, recycleBinURL(r.FormValue("prefix"))+"&job="+url.QueryEscape(job.snapshot().ID), http.StatusSeeOther)
+}
+
+// planUndelete lista os arquivos a recuperar; as pastas são expandidas com os
+// arquivos excluídos abaixo delas
+func planUndelete(ctx context.Context, bin storage.RecycleBin, files, folders []string) ([]transferPair, error) {
+	var pairs []transferPair
+
+	for _, file := range files {
+		if src := storage.CleanPath(file); src != "" {
+			pairs = append(pairs, transferPair{Src: src, Dst: src})
+		}
+	}
+
+	for _, folder := range folders {
+		prefix := folderPrefix(folder)
+		if prefix == "" {
+			return nil, errors.New("não é permitido selecionar a raiz do armazenamento")
+		}
+
+		err := bin.WalkDeleted(ctx, prefix, func(file storage.DeletedFile) error {
+			pairs = append(pairs, transferPair{Src: file.Name, Dst: file.Name})
+			return nil
+		})
+		if err != nil {
+			log.Printf("Erro ao listar a lixeira em %s: %v", prefix, err)
+			return nil, errors.New("erro ao listar os arquivos excluídos da pasta " + strings.TrimSuffix(prefix, "/"))
+		}
+	}
+
+	if len(pairs) == 0 {
+		return nil, errors.New("nenhum arquivo excluído encontrado")
+	}
+	return pairs, nil
+}
+
+func undeleteErrorMessage(err error) string {
+	if errors.Is(err, storage.ErrNotFound) {
+		return "O arquivo não está mais na lixeira"
+	}
+	return "Erro ao recuperar arquivo"
+}
+
+// recycleBin retorna o armazenamento da conta se ele mantiver os arquivos excluídos
+func (h *FileHandlers) recycleBin(w http.ResponseWriter, r *http.Request) (storage.RecycleBin, bool) {
+	store, ok := h.store(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	bin, ok := store.(storage.RecycleBin)
+	if !ok {
+		respondWithError(w, r, "O armazenamento desta conta não mantém arquivos excluídos", http.StatusNotImplemented)
+		return nil, false
+	}
+	return bin, true
+}
+
+// folderPrefix normaliza o caminho de uma pasta para uso como prefixo, com a
+// barra final; a raiz vira ""
+func folderPrefix(folder string) string {
+	folder = strings.Trim(storage.CleanPath(folder), "/")
+	if folder == "" || folder == "." {
+		return ""
+	}
+	return folder + "/"
+}
+
+func recycleBinURL(prefix string) string {
+	return "/recycle-bin?prefix=" + url.QueryEscape(folderPrefix(prefix))
+}
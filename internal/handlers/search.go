@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fileblobs/pkg/storage"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// searchDefaultLimit é a quantidade de resultados quando "limit" não é informado
+	searchDefaultLimit = 1000
+	// searchMaxLimit é o maior "limit" aceito
+	searchMaxLimit = 10000
+)
+
+// SearchResult é um arquivo encontrado pela busca, enviado em uma linha do NDJSON
+type SearchResult struct {
+	Path         string    `json:"path"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"lastModified"`
+	ContentType  string    `json:"contentType,omitempty"`
+}
+
+// SearchSummary é a última linha da busca. Truncated indica que o limite de
+// resultados foi atingido antes do fim da listagem.
+type SearchSummary struct {
+	Done      bool   `json:"done"`
+	Count     int    `json:"count"`
+	Scanned   int    `json:"scanned"`
+	Truncated bool   `json:"truncated"`
+	Error     string `json:"error,omitempty"`
+}
+
+// SearchHandler busca recursivamente os arquivos abaixo de "prefix". O modo
+// ("mode") define como "q" é comparado:
+//   - contains (padrão): o nome do arquivo contém q, sem diferenciar maiúsculas
+//   - glob: o caminho relativo a prefix casa com q, em que "*" é um trecho de
+//     nome, como em "2024/*/notas/*.pdf"
+//   - regex: o caminho relativo a prefix casa com a expressão regular q
+//
+// Os resultados são enviados à medida que a listagem avança, um objeto JSON
+// por linha (NDJSON), e a última linha traz o resumo. Fechar a conexão cancela
+// a busca; "limit" encerra a busca após tantos resultados.
+func (h *FileHandlers) SearchHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, r, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		respondWithError(w, r, "Informe o termo da busca", http.StatusBadRequest)
+		return
+	}
+
+	prefix := normalizeUploadPrefix(storage.CleanPath(r.URL.Query().Get("prefix")))
+
+	match, walkPrefix, err := searchMatcher(r.URL.Query().Get("mode"), query, prefix)
+	if err != nil {
+		respondWithError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	limit := searchDefaultLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 || n > searchMaxLimit {
+			respondWithError(w, r, "Limite inválido (de 1 a 10000)", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	store, ok := h.store(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	flusher, _ := w.(http.Flusher)
+	encoder := json.NewEncoder(w)
+	var summary SearchSummary
+
+	err = storage.Walk(r.Context(), store, walkPrefix, func(file storage.FileInfo) error {
+		if storage.IsFolderMarker(file.Name) {
+			return nil
+		}
+		summary.Scanned++
+
+		if !match(file.Name) {
+			return nil
+		}
+		if summary.Count == limit {
+			summary.Truncated = true
+			return storage.SkipAll
+		}
+		summary.Count++
+
+		if err := encoder.Encode(SearchResult{
+			Path:         file.Name,
+			Size:         file.Size,
+			LastModified: file.LastModified,
+			ContentType:  file.ContentType,
+		}); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	})
+
+	if errors.Is(err, context.Canceled) {
+		// O cliente cancelou a busca; não há a quem responder
+		return
+	}
+	if err != nil {
+		log.Printf("Erro na busca por %q em %q: %v", query, prefix, err)
+		summary.Error = "Erro ao listar arquivos; os resultados podem estar incompletos"
+	}
+
+	summary.Done = true
+	encoder.Encode(summary)
+}
+
+// searchMatcher monta a comparação do modo de busca e o prefixo a listar. No
+// modo glob, as pastas fixas do início do padrão restringem a listagem.
+func searchMatcher(mode, query, prefix string) (match func(path string) bool, walkPrefix string, err error) {
+	switch mode {
+	case "", "contains":
+		query = strings.ToLower(query)
+		return func(path string) bool {
+			return strings.Contains(strings.ToLower(baseName(path)), query)
+		}, prefix, nil
+
+	case "glob":
+		glob := strings.TrimPrefix(storage.CleanPath(query), "/")
+		re, err := storage.GlobRegexp(glob)
+		if err != nil {
+			return nil, "", errors.New("padrão de busca inválido")
+		}
+		return func(path string) bool {
+			return re.MatchString(strings.TrimPrefix(path, prefix))
+		}, prefix + storage.GlobPrefix(glob), nil
+
+	case "regex":
+		re, err := regexp.Compile(query)
+		if err != nil {
+			return nil, "", errors.New("expressão regular inválida")
+		}
+		return func(path string) bool {
+			return re.MatchString(strings.TrimPrefix(path, prefix))
+		}, prefix, nil
+	}
+
+	return nil, "", errors.New("modo de busca inválido")
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"fileblobs/internal/repository"
+	"fileblobs/pkg/storage"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// StoreResolver obtém o armazenamento que deve atender a requisição
+type StoreResolver func(r *http.Request) (storage.BlobStore, error)
+
+// FileHandlers agrupa os handlers de arquivos. O armazenamento é injetado
+// pelo StoreResolver, permitindo trocar o backend sem alterar os handlers.
+type FileHandlers struct {
+	resolveStore StoreResolver
+}
+
+// NewFileHandlers cria os handlers de arquivos usando o resolver informado
+func NewFileHandlers(resolve StoreResolver) *FileHandlers {
+	return &FileHandlers{resolveStore: resolve}
+}
+
+// store resolve o armazenamento da requisição, respondendo com erro em caso de falha
+func (h *FileHandlers) store(w http.ResponseWriter, r *http.Request) (storage.BlobStore, bool) {
+	store, err := h.resolveStore(r)
+	if err != nil {
+		log.Printf("Erro ao obter armazenamento: %v", err)
+		respondWithError(w, r, "Conta de armazenamento indisponível", http.StatusServiceUnavailable)
+		return nil, false
+	}
+	return store, true
+}
+
+// SessionStore é o StoreResolver padrão: abre o armazenamento da conta escolhida
+// pelo usuário que fez a requisição. Como nada é guardado em estado global,
+// usuários simultâneos em contas diferentes não interferem entre si.
+func SessionStore(r *http.Request) (storage.BlobStore, error) {
+	name := accountNameFromRequest(r)
+	if name == "" {
+		name = repository.DefaultAccountName
+	}
+
+	account, found := repository.GetStorageAccountByName(name)
+	if !found {
+		return nil, fmt.Errorf("conta de armazenamento não encontrada: %s", name)
+	}
+
+	return storage.Open(account.StorageConfig())
+}
+
+// requestForAccount cria uma cópia da requisição apontando para outra conta,
+// para ser resolvida pelo StoreResolver
+func requestForAccount(r *http.Request, account string) *http.Request {
+	accountRequest := r.Clone(r.Context())
+	query := accountRequest.URL.Query()
+	query.Set("account", account)
+	accountRequest.URL.RawQuery = query.Encode()
+	return accountRequest
+}
+
+// accountNameFromRequest retorna a conta indicada no parâmetro "account" da URL
+// ou, na ausência dele, a conta guardada no cookie selected_account
+func accountNameFromRequest(r *http.Request) string {
+	if name := r.URL.Query().Get("account"); name != "" {
+		return name
+	}
+
+	cookie, err := r.Cookie("selected_account")
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
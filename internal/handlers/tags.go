@@ -0,0 +1,369 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fileblobs/pkg/storage"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// tagSearchLimit limita quantos arquivos a busca por tags exibe
+const tagSearchLimit = 1000
+
+// Tag é um par chave/valor exibido nas páginas de tags
+type Tag struct {
+	Key   string
+	Value string
+}
+
+// TagsData alimenta a página de tags de um arquivo
+type TagsData struct {
+	Path      string
+	Name      string
+	FolderURL string
+	Tags      []Tag  // As tags atuais seguidas de linhas vazias até MaxTags
+	CanWrite  bool   // Permite editar as tags
+	SaveURL   string // Destino do formulário, na mesma conta
+	Saved     bool
+	Error     string
+}
+
+// TagSearchResult é um arquivo encontrado pela busca por tags
+type TagSearchResult struct {
+	Name      string
+	FolderURL string
+	Tags      []Tag
+}
+
+// TagSearchData alimenta a página de busca por tags
+type TagSearchData struct {
+	Account   string // Conta informada em "account", mantida em novas buscas
+	Where     string
+	Searched  bool
+	Results   []TagSearchResult
+	Truncated bool
+	Error     string
+}
+
+var tagsTmpl = template.Must(template.ParseFiles(templateFile("tags.html")))
+
+var tagSearchTmpl = template.Must(template.ParseFiles(templateFile("tag_search.html")))
+
+// TagsHandler exibe as tags de índice de um arquivo e, para quem pode
+// alterar arquivos, o formulário para editá-las
+func (h *FileHandlers) TagsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, r, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	blobPath := storage.CleanPath(r.URL.Query().Get("path"))
+	if blobPath == "" {
+		respondWithError(w, r, "Caminho do arquivo não especificado", http.StatusBadRequest)
+		return
+	}
+
+	tagger, ok := h.tagger(w, r)
+	if !ok {
+		return
+	}
+
+	tags, err := tagger.Tags(r.Context(), blobPath)
+	if err != nil {
+		respondWithStorageError(w, r, blobPath, err)
+		return
+	}
+
+	renderTags(w, r, blobPath, sortedTags(tags), "")
+}
+
+// SaveTagsHandler substitui as tags de um arquivo pelas enviadas nos campos
+// "key" e "value", na mesma ordem; chaves vazias são ignoradas
+func (h *FileHandlers) SaveTagsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, r, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !canWriteFiles(r) {
+		respondWithError(w, r, "Acesso negado. Você não tem permissão para alterar as tags dos arquivos.", http.StatusForbidden)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		respondWithError(w, r, "Erro ao ler formulário", http.StatusBadRequest)
+		return
+	}
+
+	blobPath := storage.CleanPath(r.FormValue("path"))
+	if blobPath == "" {
+		respondWithError(w, r, "Caminho do arquivo não especificado", http.StatusBadRequest)
+		return
+	}
+
+	keys := r.Form["key"]
+	values := r.Form["value"]
+	if len(keys) != len(values) {
+		respondWithError(w, r, "Formulário de tags inválido", http.StatusBadRequest)
+		return
+	}
+
+	tags := make(map[string]string)
+	var submitted []Tag
+	for i, key := range keys {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		if _, duplicate := tags[key]; duplicate {
+			w.WriteHeader(http.StatusBadRequest)
+			renderTags(w, r, blobPath, submitted, fmt.Sprintf("A chave %q foi informada mais de uma vez", key))
+			return
+		}
+		tags[key] = strings.TrimSpace(values[i])
+		submitted = append(submitted, Tag{Key: key, Value: tags[key]})
+	}
+
+	if err := storage.ValidateTags(tags); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		renderTags(w, r, blobPath, submitted, capitalize(err.Error()))
+		return
+	}
+
+	tagger, ok := h.tagger(w, r)
+	if !ok {
+		return
+	}
+
+	if err := tagger.SetTags(r.Context(), blobPath, tags); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			respondWithError(w, r, "Arquivo não encontrado", http.StatusNotFound)
+			return
+		}
+		log.Printf("Erro ao gravar tags de %s: %v", blobPath, err)
+		respondWithError(w, r, "Erro ao gravar tags", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Tags de %s alteradas", blobPath)
+	http.Redirect(w, r, accountURL(r, "/tags", url.Values{"saved": {"1"}, "path": {blobPath}}), http.StatusSeeOther)
+}
+
+// BulkTagsHandler aplica tags a arquivos ("paths") e pastas inteiras
+// ("folders"), mantendo as demais tags de cada arquivo. "set" traz uma tag
+// chave=valor por linha e "remove" uma chave por linha. A alteração roda em
+// segundo plano e o progresso é consultado em /job-status.
+func (h *FileHandlers) BulkTagsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, r, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !canWriteFiles(r) {
+		respondWithError(w, r, "Acesso negado. Você não tem permissão para alterar as tags dos arquivos.", http.StatusForbidden)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		respondWithError(w, r, "Erro ao ler formulário", http.StatusBadRequest)
+		return
+	}
+
+	files := r.Form["paths"]
+	folders := r.Form["folders"]
+	if len(files) == 0 && len(folders) == 0 {
+		respondWithError(w, r, "Nenhum arquivo selecionado", http.StatusBadRequest)
+		return
+	}
+
+	set, err := parseTagLines(r.FormValue("set"))
+	if err == nil {
+		err = storage.ValidateTags(set)
+	}
+	if err != nil {
+		respondWithError(w, r, capitalize(err.Error()), http.StatusBadRequest)
+		return
+	}
+	var remove []string
+	for _, line := range strings.Split(r.FormValue("remove"), "\n") {
+		if key := strings.TrimSpace(line); key != "" {
+			remove = append(remove, key)
+		}
+	}
+	if len(set) == 0 && len(remove) == 0 {
+		respondWithError(w, r, "Informe as tags a adicionar ou remover", http.StatusBadRequest)
+		return
+	}
+
+	store, ok := h.store(w, r)
+	if !ok {
+		return
+	}
+	tagger, ok := store.(storage.Tagger)
+	if !ok {
+		respondWithError(w, r, "O armazenamento desta conta não tem tags de índice", http.StatusNotImplemented)
+		return
+	}
+
+	pairs, err := planFiles(r.Context(), store, files, folders)
+	if err != nil {
+		respondWithError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	owner, _ := getSessionUser(r)
+	job, err := startJob(owner, pairs, func(ctx context.Context, pair transferPair) error {
+		return applyTags(ctx, tagger, pair.Src, set, remove)
+	}, tagErrorMessage)
+	if err != nil {
+		respondWithError(w, r, "Erro ao iniciar alteração de tags", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Usuário %s iniciou a alteração de tags de %d arquivo(s)", owner, len(pairs))
+	respondWithJob(w, job)
+}
+
+// TagSearchHandler busca no container os arquivos que atendem à expressão
+// "where" de Find Blobs by Tags e os lista com links para as suas pastas
+func (h *FileHandlers) TagSearchHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, r, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tagger, ok := h.tagger(w, r)
+	if !ok {
+		return
+	}
+
+	data := TagSearchData{
+		Account: r.URL.Query().Get("account"),
+		Where:   strings.TrimSpace(r.URL.Query().Get("where")),
+	}
+	if data.Where == "" {
+		tagSearchTmpl.Execute(w, data)
+		return
+	}
+	data.Searched = true
+
+	err := tagger.FindByTags(r.Context(), data.Where, func(file storage.TaggedFile) error {
+		if len(data.Results) == tagSearchLimit {
+			data.Truncated = true
+			return storage.SkipAll
+		}
+		data.Results = append(data.Results, TagSearchResult{
+			Name:      file.Name,
+			FolderURL: accountFolderURL(r, file.Name),
+			Tags:      sortedTags(file.Tags),
+		})
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, storage.ErrInvalidTagQuery) {
+			log.Printf("Erro na busca por tags %q: %v", data.Where, err)
+			respondWithError(w, r, "Erro ao buscar arquivos por tags", http.StatusInternalServerError)
+			return
+		}
+		data.Error = "Expressão inválida. Use, por exemplo, \"cliente\" = 'Acme' AND \"ano\" >= '2023'."
+		data.Results = nil
+		w.WriteHeader(http.StatusBadRequest)
+	}
+
+	tagSearchTmpl.Execute(w, data)
+}
+
+// applyTags acrescenta e remove tags de um arquivo, mantendo as demais
+func applyTags(ctx context.Context, tagger storage.Tagger, path string, set map[string]string, remove []string) error {
+	tags, err := tagger.Tags(ctx, path)
+	if err != nil {
+		return err
+	}
+	for key, value := range set {
+		tags[key] = value
+	}
+	for _, key := range remove {
+		delete(tags, key)
+	}
+	if len(tags) > storage.MaxTags {
+		return storage.ErrTooManyTags
+	}
+	return tagger.SetTags(ctx, path, tags)
+}
+
+func tagErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return "Arquivo não encontrado"
+	case errors.Is(err, storage.ErrTooManyTags):
+		return fmt.Sprintf("O arquivo ficaria com mais de %d tags", storage.MaxTags)
+	}
+	return "Erro ao gravar tags"
+}
+
+// parseTagLines lê uma tag chave=valor por linha, ignorando linhas vazias
+func parseTagLines(text string) (map[string]string, error) {
+	tags := make(map[string]string)
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("a linha %q deve estar no formato chave=valor", line)
+		}
+		tags[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return tags, nil
+}
+
+func renderTags(w http.ResponseWriter, r *http.Request, blobPath string, tags []Tag, errMessage string) {
+	canWrite := canWriteFiles(r)
+	if canWrite {
+		for len(tags) < storage.MaxTags {
+			tags = append(tags, Tag{})
+		}
+	}
+
+	tagsTmpl.Execute(w, TagsData{
+		Path:      blobPath,
+		Name:      baseName(blobPath),
+		FolderURL: folderURL(blobPath),
+		Tags:      tags,
+		CanWrite:  canWrite,
+		SaveURL:   accountURL(r, "/save-tags", nil),
+		Saved:     r.URL.Query().Get("saved") == "1",
+		Error:     errMessage,
+	})
+}
+
+// tagger retorna o armazenamento da conta se ele tiver tags de índice
+func (h *FileHandlers) tagger(w http.ResponseWriter, r *http.Request) (storage.Tagger, bool) {
+	store, ok := h.store(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	tagger, ok := store.(storage.Tagger)
+	if !ok {
+		respondWithError(w, r, "O armazenamento desta conta não tem tags de índice", http.StatusNotImplemented)
+		return nil, false
+	}
+	return tagger, true
+}
+
+func sortedTags(tags map[string]string) []Tag {
+	sorted := make([]Tag, 0, len(tags))
+	for key, value := range tags {
+		sorted = append(sorted, Tag{Key: key, Value: value})
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Key < sorted[j].Key })
+	return sorted
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fileblobs/internal/repository"
+	"fileblobs/pkg/storage"
+	"fileblobs/pkg/thumbnail"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const (
+	// defaultThumbnailCacheDir guarda as miniaturas geradas, fora do armazenamento dos usuários
+	defaultThumbnailCacheDir = "./data/thumbnails"
+	// defaultThumbnailSize é o tamanho usado na visualização em grade
+	defaultThumbnailSize = 256
+)
+
+// thumbnailSizes são os tamanhos aceitos, o que limita as variações em cache:
+// 64 para a lista e 256 para a grade
+var thumbnailSizes = map[int]bool{64: true, defaultThumbnailSize: true}
+
+// thumbnailSlots limita as miniaturas geradas ao mesmo tempo, já que
+// decodificar uma foto grande consome bastante memória e CPU
+var thumbnailSlots = make(chan struct{}, 4)
+
+// ThumbnailHandler devolve uma miniatura JPEG de uma imagem JPEG, PNG ou GIF.
+// A miniatura é gerada na primeira requisição e guardada em disco, com uma
+// chave que inclui o ETag do arquivo: quando o arquivo muda, uma nova é gerada.
+func (h *FileHandlers) ThumbnailHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		respondWithError(w, r, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	blobPath := r.URL.Query().Get("path")
+	if blobPath == "" {
+		respondWithError(w, r, "Caminho do arquivo não especificado", http.StatusBadRequest)
+		return
+	}
+
+	size := defaultThumbnailSize
+	if value := r.URL.Query().Get("size"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || !thumbnailSizes[n] {
+			respondWithError(w, r, "Tamanho de miniatura inválido (64 ou 256)", http.StatusBadRequest)
+			return
+		}
+		size = n
+	}
+
+	store, ok := h.store(w, r)
+	if !ok {
+		return
+	}
+
+	info, err := store.Stat(r.Context(), blobPath)
+	if err != nil {
+		respondWithStorageError(w, r, blobPath, err)
+		return
+	}
+	if !hasThumbnail(info) {
+		respondWithError(w, r, "Miniaturas só estão disponíveis para imagens JPEG, PNG e GIF", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	key := thumbnailKey(thumbnailLocation(r), info, size)
+	cachePath := filepath.Join(thumbnailCacheDir(), key[:2], key+".jpg")
+
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		data, err = generateThumbnail(r, store, info, size, cachePath)
+		if errors.Is(err, thumbnail.ErrUnsupported) || errors.Is(err, thumbnail.ErrTooLarge) {
+			respondWithError(w, r, "Não foi possível gerar a miniatura: "+err.Error(), http.StatusUnsupportedMediaType)
+			return
+		}
+		if err != nil {
+			log.Printf("Erro ao gerar miniatura de %s: %v", blobPath, err)
+			respondWithError(w, r, "Erro ao gerar miniatura", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	// Sem prazo de cache: o navegador revalida pelo ETag, que muda com o arquivo
+	w.Header().Set("Cache-Control", "private, no-cache")
+	w.Header().Set("ETag", `"`+key+`"`)
+	w.Header().Set("Content-Type", "image/jpeg")
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+}
+
+// generateThumbnail gera a miniatura e a grava no cache. Uma falha ao gravar
+// não impede a resposta; a miniatura só será gerada de novo na próxima vez.
+func generateThumbnail(r *http.Request, store storage.BlobStore, info storage.FileInfo, size int, cachePath string) ([]byte, error) {
+	select {
+	case thumbnailSlots <- struct{}{}:
+		defer func() { <-thumbnailSlots }()
+	case <-r.Context().Done():
+		return nil, r.Context().Err()
+	}
+
+	body, _, err := store.Open(r.Context(), info.Name)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := thumbnail.Generate(body, size)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeThumbnailCache(cachePath, data); err != nil {
+		log.Printf("Erro ao gravar miniatura em cache: %v", err)
+	}
+	return data, nil
+}
+
+// writeThumbnailCache grava em um arquivo temporário e renomeia, para que
+// requisições simultâneas nunca leiam uma miniatura pela metade
+func writeThumbnailCache(cachePath string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(cachePath), "*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), cachePath)
+}
+
+// thumbnailKey identifica a miniatura pelo armazenamento (thumbnailLocation),
+// caminho, ETag e tamanho
+func thumbnailKey(location []string, info storage.FileInfo, size int) string {
+	hash := sha256.New()
+	for _, part := range append(location,
+		info.Name,
+		info.ETag,
+		strconv.FormatInt(info.Size, 10),
+		strconv.Itoa(size),
+	) {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// thumbnailLocation identifica o armazenamento aberto para a requisição pela
+// conta selecionada: a conta no provedor e o container, bucket ou diretório
+// configurados nela. Contas que apontam para o mesmo lugar dividem o cache.
+func thumbnailLocation(r *http.Request) []string {
+	name := accountNameFromRequest(r)
+	if name == "" {
+		name = repository.DefaultAccountName
+	}
+	account, _ := repository.GetStorageAccountByName(name)
+
+	return []string{
+		account.StorageType(),
+		account.AccountName,
+		account.Endpoint,
+		accountContainer(account),
+		account.RootPath,
+	}
+}
+
+// thumbnailCacheDir lê THUMBNAIL_CACHE_DIR, usando data/thumbnails por padrão
+func thumbnailCacheDir() string {
+	if dir := os.Getenv("THUMBNAIL_CACHE_DIR"); dir != "" {
+		return dir
+	}
+	return defaultThumbnailCacheDir
+}
+
+// hasThumbnail indica se o arquivo é uma imagem JPEG, PNG ou GIF
+func hasThumbnail(info storage.FileInfo) bool {
+	switch storedMediaType(info) {
+	case "image/jpeg", "image/png", "image/gif":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"fileblobs/pkg/storage"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestHasThumbnail(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		want        bool
+	}{
+		{"foto.jpg", "image/jpeg", true},
+		{"foto.png", "image/png; charset=binary", true},
+		{"foto.gif", "", true},
+		{"foto.jpg", "application/octet-stream", true},
+		{"foto.jpg", "application/octet-stream; charset=binary", true},
+		{"foto.webp", "image/webp", false},
+		{"nota.txt", "application/octet-stream", false},
+		{"foto.jpg", "text/plain", false},
+	}
+	for _, tt := range tests {
+		info := storage.FileInfo{Name: tt.name, ContentType: tt.contentType}
+		if got := hasThumbnail(info); got != tt.want {
+			t.Errorf("hasThumbnail(%q, %q) = %v, esperado %v", tt.name, tt.contentType, got, tt.want)
+		}
+	}
+}
+
+func TestThumbnailKey(t *testing.T) {
+	info := storage.FileInfo{Name: "foto.jpg", ETag: "abc", Size: 10}
+	location := []string{"azure", "conta", "", "fotos", ""}
+	key := thumbnailKey(location, info, 64)
+
+	if thumbnailKey([]string{"azure", "conta", "", "outro", ""}, info, 64) == key {
+		t.Error("containers diferentes com a mesma chave")
+	}
+	if thumbnailKey(location, info, 256) == key {
+		t.Error("tamanhos diferentes com a mesma chave")
+	}
+	changed := info
+	changed.ETag = "def"
+	if thumbnailKey(location, changed, 64) == key {
+		t.Error("ETags diferentes com a mesma chave")
+	}
+}
+
+func TestThumbnailLocationIgnoresContainerParam(t *testing.T) {
+	// O repositório de contas grava em ./data; o diretório temporário
+	// mantém o teste longe dos dados reais
+	t.Chdir(t.TempDir())
+
+	// O container vem da conta, não de um parâmetro que o armazenamento ignora
+	plain := httptest.NewRequest("GET", "/thumbnail?path=foto.jpg", nil)
+	other := httptest.NewRequest("GET", "/thumbnail?path=foto.jpg&container=outro", nil)
+	if !slices.Equal(thumbnailLocation(plain), thumbnailLocation(other)) {
+		t.Error("o parâmetro container mudou a localização da miniatura")
+	}
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fileblobs/pkg/storage"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// SetTierHandler move arquivos ("paths") e pastas inteiras ("folders") para a
+// camada "tier". Arquivos que saem da camada Archive são reidratados com a
+// prioridade "priority" (Standard ou High). A alteração roda em segundo plano
+// e o progresso é consultado em /job-status.
+func (h *FileHandlers) SetTierHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, r, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !canWriteFiles(r) {
+		respondWithError(w, r, "Acesso negado. Você não tem permissão para alterar a camada dos arquivos.", http.StatusForbidden)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		respondWithError(w, r, "Erro ao ler formulário", http.StatusBadRequest)
+		return
+	}
+
+	files := r.Form["paths"]
+	folders := r.Form["folders"]
+	tier := r.FormValue("tier")
+	priority := r.FormValue("priority")
+
+	if len(files) == 0 && len(folders) == 0 {
+		respondWithError(w, r, "Nenhum arquivo selecionado", http.StatusBadRequest)
+		return
+	}
+	if !slices.Contains(storage.Tiers, tier) {
+		respondWithError(w, r, "Camada inválida", http.StatusBadRequest)
+		return
+	}
+	if priority != "" && priority != storage.RehydrateStandard && priority != storage.RehydrateHigh {
+		respondWithError(w, r, "Prioridade de reidratação inválida", http.StatusBadRequest)
+		return
+	}
+	if tier == storage.TierArchive {
+		// A prioridade só vale para a reidratação
+		priority = ""
+	}
+
+	store, ok := h.store(w, r)
+	if !ok {
+		return
+	}
+	tierer, ok := store.(storage.Tierer)
+	if !ok {
+		respondWithError(w, r, "O armazenamento desta conta não tem camadas de acesso", http.StatusNotImplemented)
+		return
+	}
+
+	pairs, err := planFiles(r.Context(), store, files, folders)
+	if err != nil {
+		respondWithError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	owner, _ := getSessionUser(r)
+	job, err := startJob(owner, pairs, func(ctx context.Context, pair transferPair) error {
+		return tierer.SetTier(ctx, pair.Src, tier, priority)
+	}, tierErrorMessage)
+	if err != nil {
+		respondWithError(w, r, "Erro ao iniciar alteração de camada", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Usuário %s iniciou a alteração de %d arquivo(s) para a camada %s", owner, len(pairs), tier)
+	respondWithJob(w, job)
+}
+
+// planFiles lista os arquivos selecionados para alterar as suas propriedades;
+// as pastas são expandidas recursivamente, sem os marcadores de pasta
+func planFiles(ctx context.Context, store storage.BlobStore, files, folders []string) ([]transferPair, error) {
+	var pairs []transferPair
+
+	for _, file := range files {
+		if src := storage.CleanPath(file); src != "" {
+			pairs = append(pairs, transferPair{Src: src, Dst: src})
+		}
+	}
+
+	for _, folder := range folders {
+		prefix := folderPrefix(folder)
+		if prefix == "" {
+			return nil, errors.New("não é permitido selecionar a raiz do armazenamento")
+		}
+
+		err := storage.Walk(ctx, store, prefix, func(file storage.FileInfo) error {
+			if !storage.IsFolderMarker(file.Name) {
+				pairs = append(pairs, transferPair{Src: file.Name, Dst: file.Name})
+			}
+			return nil
+		})
+		if err != nil {
+			log.Printf("Erro ao listar %s: %v", prefix, err)
+			return nil, fmt.Errorf("erro ao listar a pasta %s", strings.TrimSuffix(prefix, "/"))
+		}
+	}
+
+	if len(pairs) == 0 {
+		return nil, errors.New("nenhum arquivo selecionado")
+	}
+	return pairs, nil
+}
+
+func tierErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return "Arquivo não encontrado"
+	case errors.Is(err, storage.ErrRehydrating):
+		return "O arquivo já está sendo reidratado; aguarde a conclusão para alterar a camada"
+	}
+	return "Erro ao alterar a camada do arquivo"
+}
+
+// rejectArchived responde com 409 e a explicação quando o arquivo está na
+// camada Archive, cujo conteúdo só pode ser lido depois da reidratação
+func rejectArchived(w http.ResponseWriter, r *http.Request, info storage.FileInfo) bool {
+	if !storage.IsArchived(info) {
+		return false
+	}
+	respondWithError(w, r, archivedMessage(info), http.StatusConflict)
+	return true
+}
+
+// rejectArchivedFiles faz o mesmo para os arquivos de um ZIP, antes que o
+// cabeçalho da resposta seja enviado, em vez de omiti-los do ZIP
+func rejectArchivedFiles(w http.ResponseWriter, r *http.Request, files []storage.FileInfo) bool {
+	for _, file := range files {
+		if storage.IsArchived(file) {
+			respondWithError(w, r, file.Name+": "+archivedMessage(file), http.StatusConflict)
+			return true
+		}
+	}
+	return false
+}
+
+func archivedMessage(info storage.FileInfo) string {
+	if info.ArchiveStatus != "" {
+		return fmt.Sprintf("Este arquivo está na camada Archive e já está sendo reidratado para a camada %s. "+
+			"Ele poderá ser baixado quando a reidratação terminar, o que pode levar até 15 horas.", rehydrateTarget(info.ArchiveStatus))
+	}
+	return "Este arquivo está na camada Archive, cujo conteúdo não pode ser lido. " +
+		"Para baixá-lo, mova-o para a camada Hot, Cool ou Cold (Editar > Camada) e aguarde a reidratação, que pode levar até 15 horas."
+}
+
+// rehydratingMessage explica um ErrRehydrating, quando a camada de destino
+// da reidratação não é conhecida
+const rehydratingMessage = "Este arquivo está na camada Archive e já está sendo reidratado. " +
+	"Ele poderá ser baixado quando a reidratação terminar, o que pode levar até 15 horas."
+
+// rehydrateTarget extrai a camada de destino de um ArchiveStatus como "rehydrate-pending-to-hot"
+func rehydrateTarget(archiveStatus string) string {
+	return capitalize(archiveStatus[strings.LastIndex(archiveStatus, "-")+1:])
+}
@@ -0,0 +1,422 @@
+package handlers
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fileblobs/internal/repository"
+	"fileblobs/pkg/storage"
+	"hash"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tusVersion é a versão do protocolo tus (https://tus.io) implementada em /uploads
+const tusVersion = "1.0.0"
+
+// tusChunkSize é o tamanho máximo de cada parte enviada ao armazenamento.
+// Um PATCH maior é dividido em várias partes, limitando a memória usada.
+const tusChunkSize = 8 << 20
+
+// activeUploads impede que dois PATCH gravem no mesmo upload ao mesmo tempo
+var activeUploads sync.Map
+
+// SetTusCapabilities informa as capacidades do servidor tus, usadas na resposta ao OPTIONS
+func SetTusCapabilities(w http.ResponseWriter) {
+	w.Header().Set("Tus-Resumable", tusVersion)
+	w.Header().Set("Tus-Version", tusVersion)
+	w.Header().Set("Tus-Extension", "creation,termination")
+	w.Header().Set("Tus-Max-Size", strconv.FormatInt(uploadMaxBytes(), 10))
+}
+
+// TusHandler implementa uploads retomáveis compatíveis com o protocolo tus 1.0:
+// POST /uploads cria o upload, HEAD /uploads/{id} informa quantos bytes já foram
+// recebidos, PATCH /uploads/{id} envia a continuação e DELETE cancela o upload.
+// Cada trecho recebido vira uma parte no armazenamento (um bloco no Azure),
+// e o arquivo só é montado quando o último byte chega.
+func (h *FileHandlers) TusHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Tus-Resumable", tusVersion)
+
+	if r.Method == http.MethodOptions {
+		SetTusCapabilities(w)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	// Clientes atrás de proxies que só aceitam GET e POST enviam o método neste cabeçalho
+	if override := r.Header.Get("X-HTTP-Method-Override"); override != "" && r.Method == http.MethodPost {
+		r.Method = strings.ToUpper(override)
+	}
+
+	if r.Header.Get("Tus-Resumable") != tusVersion {
+		w.Header().Set("Tus-Version", tusVersion)
+		http.Error(w, "Versão do protocolo tus não suportada", http.StatusPreconditionFailed)
+		return
+	}
+
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/uploads"), "/")
+	if id == "" {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+			return
+		}
+		h.createUpload(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodHead:
+		h.uploadOffset(w, r, id)
+	case http.MethodPatch:
+		h.patchUpload(w, r, id)
+	case http.MethodDelete:
+		h.terminateUpload(w, r, id)
+	default:
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *FileHandlers) createUpload(w http.ResponseWriter, r *http.Request) {
+	length, err := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+	if err != nil || length < 0 {
+		http.Error(w, "Cabeçalho Upload-Length inválido", http.StatusBadRequest)
+		return
+	}
+	if length > uploadMaxBytes() {
+		http.Error(w, "Upload excede o tamanho máximo permitido", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	metadata := parseUploadMetadata(r.Header.Get("Upload-Metadata"))
+	filename := metadata["filename"]
+	if filename == "" {
+		http.Error(w, "Nome do arquivo não informado em Upload-Metadata", http.StatusBadRequest)
+		return
+	}
+
+	prefix, ok := metadata["prefix"]
+	if !ok {
+		prefix = r.URL.Query().Get("prefix")
+	}
+	path := normalizeUploadPrefix(prefix) + filepath.Base(filename)
+
+	store, ok := h.store(w, r)
+	if !ok {
+		return
+	}
+	uploader, ok := store.(storage.ChunkedUploader)
+	if !ok {
+		http.Error(w, "Uploads retomáveis não são suportados por esta conta", http.StatusNotImplemented)
+		return
+	}
+
+	storeUploadID, err := uploader.BeginChunks(r.Context(), path)
+	if err != nil {
+		log.Printf("Erro ao iniciar upload de %s: %v", path, err)
+		http.Error(w, "Erro ao iniciar upload", http.StatusInternalServerError)
+		return
+	}
+
+	id, err := newRandomID()
+	if err != nil {
+		http.Error(w, "Erro ao iniciar upload", http.StatusInternalServerError)
+		return
+	}
+
+	account := accountNameFromRequest(r)
+	if account == "" {
+		account = repository.DefaultAccountName
+	}
+	owner, _ := getSessionUser(r)
+
+	upload := repository.Upload{
+		ID:            id,
+		Owner:         owner,
+		Account:       account,
+		Path:          path,
+		Length:        length,
+		StoreUploadID: storeUploadID,
+		CreatedAt:     time.Now(),
+	}
+
+	// Um arquivo vazio já está completo ao ser criado; o estado é guardado
+	// mesmo assim, para que HEAD em Location informe o upload concluído
+	if length == 0 {
+		emptyMD5 := md5.Sum(nil)
+		if err := uploader.CommitChunks(r.Context(), path, storeUploadID, 0, storage.DetectContentType(path, nil), emptyMD5[:]); err != nil {
+			log.Printf("Erro ao concluir upload de %s: %v", path, err)
+			http.Error(w, "Erro ao concluir upload", http.StatusInternalServerError)
+			return
+		}
+		upload.Committed = true
+	}
+	if err := repository.SaveUpload(upload); err != nil {
+		log.Printf("Erro ao salvar estado do upload: %v", err)
+		http.Error(w, "Erro ao iniciar upload", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", "/uploads/"+id)
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (h *FileHandlers) uploadOffset(w http.ResponseWriter, r *http.Request, id string) {
+	upload, ok := findUpload(w, r, id)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	w.Header().Set("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *FileHandlers) patchUpload(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Header.Get("Content-Type") != "application/offset+octet-stream" {
+		http.Error(w, "Content-Type deve ser application/offset+octet-stream", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	if _, busy := activeUploads.LoadOrStore(id, struct{}{}); busy {
+		http.Error(w, "Upload em andamento em outra requisição", http.StatusLocked)
+		return
+	}
+	defer activeUploads.Delete(id)
+
+	upload, ok := findUpload(w, r, id)
+	if !ok {
+		return
+	}
+
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset != upload.Offset {
+		w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+		http.Error(w, "Upload-Offset não corresponde ao recebido pelo servidor", http.StatusConflict)
+		return
+	}
+
+	if upload.Committed {
+		w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	uploader, ok := h.uploaderFor(w, r, upload)
+	if !ok {
+		return
+	}
+
+	// Nos backends com tamanho mínimo de parte, um trecho menor que ele só
+	// pode ser gravado se for o final do arquivo. O restante de um PATCH é
+	// descartado, e o Upload-Offset da resposta indica de onde o cliente
+	// deve continuar.
+	minChunk := 0
+	if sizer, ok := uploader.(storage.ChunkSizer); ok {
+		minChunk = sizer.MinChunkSize()
+	}
+
+	// O que já foi recebido deve ser gravado mesmo que o cliente desconecte
+	ctx := context.WithoutCancel(r.Context())
+	body := http.MaxBytesReader(w, r.Body, upload.Length-upload.Offset)
+	buf := make([]byte, tusChunkSize)
+	startOffset := upload.Offset
+	discarded := 0
+	contentHash := uploadHash(upload)
+
+	for upload.Offset < upload.Length {
+		n, readErr := readChunk(body, buf)
+		if n > 0 && n < minChunk && upload.Offset+int64(n) < upload.Length {
+			discarded = n
+		} else if n > 0 {
+			err := uploader.StageChunk(ctx, upload.Path, upload.StoreUploadID, upload.Chunks, buf[:n])
+			if err != nil {
+				log.Printf("Erro ao gravar parte do upload %s: %v", id, err)
+				http.Error(w, "Erro ao gravar parte do upload", http.StatusInternalServerError)
+				return
+			}
+
+			if upload.Chunks == 0 {
+				// O tipo é detectado pelo início da primeira parte e guardado até a montagem
+				upload.ContentType = storage.DetectContentType(upload.Path, buf[:n])
+			}
+			if contentHash != nil {
+				contentHash.Write(buf[:n])
+				upload.MD5State, _ = contentHash.(encoding.BinaryMarshaler).MarshalBinary()
+			}
+			upload.Offset += int64(n)
+			upload.Chunks++
+			if err := repository.SaveUpload(upload); err != nil {
+				log.Printf("Erro ao salvar estado do upload %s: %v", id, err)
+				http.Error(w, "Erro ao gravar parte do upload", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(readErr, &maxBytesErr) {
+				http.Error(w, "Conteúdo excede o tamanho declarado do upload", http.StatusRequestEntityTooLarge)
+				return
+			}
+			log.Printf("Upload %s interrompido em %d bytes: %v", id, upload.Offset, readErr)
+			http.Error(w, "Upload interrompido", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if discarded > 0 && upload.Offset == startOffset {
+		w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+		http.Error(w, "Cada PATCH deve enviar pelo menos "+strconv.Itoa(minChunk>>20)+" MB, exceto o último", http.StatusBadRequest)
+		return
+	}
+
+	if upload.Offset == upload.Length {
+		var contentMD5 []byte
+		if contentHash != nil {
+			contentMD5 = contentHash.Sum(nil)
+		}
+		err := uploader.CommitChunks(ctx, upload.Path, upload.StoreUploadID, upload.Chunks, upload.ContentType, contentMD5)
+		if err != nil {
+			// O estado é mantido: um novo PATCH vazio tenta montar o arquivo outra vez
+			log.Printf("Erro ao concluir upload %s: %v", id, err)
+			http.Error(w, "Erro ao concluir upload", http.StatusInternalServerError)
+			return
+		}
+		if err := repository.DeleteUpload(id); err != nil {
+			log.Printf("Erro ao remover estado do upload %s: %v", id, err)
+		}
+	}
+
+	w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *FileHandlers) terminateUpload(w http.ResponseWriter, r *http.Request, id string) {
+	if _, busy := activeUploads.LoadOrStore(id, struct{}{}); busy {
+		http.Error(w, "Upload em andamento em outra requisição", http.StatusLocked)
+		return
+	}
+	defer activeUploads.Delete(id)
+
+	upload, ok := findUpload(w, r, id)
+	if !ok {
+		return
+	}
+
+	if upload.Committed {
+		if err := repository.DeleteUpload(id); err != nil {
+			log.Printf("Erro ao remover estado do upload %s: %v", id, err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	uploader, ok := h.uploaderFor(w, r, upload)
+	if !ok {
+		return
+	}
+
+	if err := uploader.AbortChunks(r.Context(), upload.Path, upload.StoreUploadID); err != nil {
+		log.Printf("Erro ao descartar partes do upload %s: %v", id, err)
+	}
+	if err := repository.DeleteUpload(id); err != nil {
+		log.Printf("Erro ao remover estado do upload %s: %v", id, err)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// findUpload busca o upload, que só pode ser continuado pelo usuário que o criou
+func findUpload(w http.ResponseWriter, r *http.Request, id string) (repository.Upload, bool) {
+	upload, found := repository.GetUpload(id)
+	owner, _ := getSessionUser(r)
+	if !found || upload.Owner != owner {
+		http.Error(w, "Upload não encontrado", http.StatusNotFound)
+		return repository.Upload{}, false
+	}
+	return upload, true
+}
+
+// uploaderFor abre o armazenamento da conta em que o upload foi criado,
+// mesmo que o usuário tenha selecionado outra conta nesse meio tempo
+func (h *FileHandlers) uploaderFor(w http.ResponseWriter, r *http.Request, upload repository.Upload) (storage.ChunkedUploader, bool) {
+	store, ok := h.store(w, requestForAccount(r, upload.Account))
+	if !ok {
+		return nil, false
+	}
+
+	uploader, ok := store.(storage.ChunkedUploader)
+	if !ok {
+		http.Error(w, "Uploads retomáveis não são suportados por esta conta", http.StatusNotImplemented)
+		return nil, false
+	}
+	return uploader, true
+}
+
+// uploadHash retoma o MD5 das partes já gravadas, guardado entre as requisições
+// em MD5State. Retorna nil se ele não puder ser retomado, como nos uploads
+// iniciados antes de o estado ser guardado; o arquivo é montado sem o MD5.
+func uploadHash(upload repository.Upload) hash.Hash {
+	contentHash := md5.New()
+	if upload.Chunks == 0 {
+		return contentHash
+	}
+	if err := contentHash.(encoding.BinaryUnmarshaler).UnmarshalBinary(upload.MD5State); err != nil {
+		return nil
+	}
+	return contentHash
+}
+
+// parseUploadMetadata decodifica o cabeçalho Upload-Metadata
+// ("chave valorBase64,chave valorBase64")
+func parseUploadMetadata(header string) map[string]string {
+	metadata := make(map[string]string)
+	for _, pair := range strings.Split(header, ",") {
+		key, encoded, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			continue
+		}
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			continue
+		}
+		metadata[key] = string(value)
+	}
+	return metadata
+}
+
+// readChunk preenche buf até o fim ou até o primeiro erro de leitura. Ao contrário
+// de io.ReadFull, devolve io.EOF apenas quando o corpo terminou normalmente.
+func readChunk(r io.Reader, buf []byte) (int, error) {
+	n := 0
+	for n < len(buf) {
+		m, err := r.Read(buf[n:])
+		n += m
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+func newRandomID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"fileblobs/pkg/storage"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// chunkedMemStore acrescenta ao memStore os uploads em partes, guardando as
+// partes recebidas e o MD5 informado na montagem
+type chunkedMemStore struct {
+	*memStore
+	staged     map[string]map[int][]byte
+	contentMD5 map[string][]byte
+}
+
+func newChunkedMemStore() *chunkedMemStore {
+	return &chunkedMemStore{
+		memStore:   newMemStore(nil),
+		staged:     make(map[string]map[int][]byte),
+		contentMD5: make(map[string][]byte),
+	}
+}
+
+func (s *chunkedMemStore) BeginChunks(ctx context.Context, path string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	uploadID := fmt.Sprintf("upload-%d", len(s.staged)+1)
+	s.staged[uploadID] = make(map[int][]byte)
+	return uploadID, nil
+}
+
+func (s *chunkedMemStore) StageChunk(ctx context.Context, path, uploadID string, index int, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	chunks, ok := s.staged[uploadID]
+	if !ok {
+		return fmt.Errorf("upload %s: %w", uploadID, storage.ErrNotFound)
+	}
+	chunks[index] = bytes.Clone(data)
+	return nil
+}
+
+func (s *chunkedMemStore) CommitChunks(ctx context.Context, path, uploadID string, count int, contentType string, contentMD5 []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	chunks, ok := s.staged[uploadID]
+	if !ok {
+		return fmt.Errorf("upload %s: %w", uploadID, storage.ErrNotFound)
+	}
+	var data []byte
+	for i := range count {
+		data = append(data, chunks[i]...)
+	}
+	s.put(path, data)
+	s.contentMD5[path] = contentMD5
+	delete(s.staged, uploadID)
+	return nil
+}
+
+func (s *chunkedMemStore) AbortChunks(ctx context.Context, path, uploadID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.staged, uploadID)
+	return nil
+}
+
+// tusRequest envia ao TusHandler uma requisição com os cabeçalhos do protocolo
+func tusRequest(h *FileHandlers, method, target string, body string, headers map[string]string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	r.Header.Set("Tus-Resumable", tusVersion)
+	for key, value := range headers {
+		r.Header.Set(key, value)
+	}
+	w := httptest.NewRecorder()
+	h.TusHandler(w, r)
+	return w
+}
+
+func TestTusUpload(t *testing.T) {
+	// O estado dos uploads é gravado em ./data
+	t.Chdir(t.TempDir())
+
+	store := newChunkedMemStore()
+	h := NewFileHandlers(func(r *http.Request) (storage.BlobStore, error) { return store, nil })
+
+	metadata := "filename " + base64.StdEncoding.EncodeToString([]byte("nota.txt")) +
+		",prefix " + base64.StdEncoding.EncodeToString([]byte("docs"))
+	w := tusRequest(h, http.MethodPost, "/uploads", "", map[string]string{
+		"Upload-Length":   "11",
+		"Upload-Metadata": metadata,
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST: status %d, esperado %d", w.Code, http.StatusCreated)
+	}
+	location := w.Header().Get("Location")
+	if !strings.HasPrefix(location, "/uploads/") {
+		t.Fatalf("POST: Location = %q", location)
+	}
+
+	w = tusRequest(h, http.MethodHead, location, "", nil)
+	if w.Code != http.StatusOK || w.Header().Get("Upload-Offset") != "0" || w.Header().Get("Upload-Length") != "11" {
+		t.Fatalf("HEAD: status %d, Upload-Offset %q, Upload-Length %q", w.Code, w.Header().Get("Upload-Offset"), w.Header().Get("Upload-Length"))
+	}
+
+	patch := func(offset, body string) *httptest.ResponseRecorder {
+		return tusRequest(h, http.MethodPatch, location, body, map[string]string{
+			"Content-Type":  "application/offset+octet-stream",
+			"Upload-Offset": offset,
+		})
+	}
+
+	w = patch("0", "hello ")
+	if w.Code != http.StatusNoContent || w.Header().Get("Upload-Offset") != "6" {
+		t.Fatalf("primeiro PATCH: status %d, Upload-Offset %q", w.Code, w.Header().Get("Upload-Offset"))
+	}
+	if _, ok := store.files["docs/nota.txt"]; ok {
+		t.Fatal("arquivo montado antes do último byte")
+	}
+
+	w = tusRequest(h, http.MethodHead, location, "", nil)
+	if got := w.Header().Get("Upload-Offset"); got != "6" {
+		t.Errorf("HEAD após o primeiro PATCH: Upload-Offset = %q, esperado 6", got)
+	}
+
+	// Um PATCH repetido a partir do início não pode ser acrescentado
+	w = patch("0", "hello ")
+	if w.Code != http.StatusConflict || w.Header().Get("Upload-Offset") != "6" {
+		t.Fatalf("PATCH fora de posição: status %d, Upload-Offset %q; esperado %d e 6", w.Code, w.Header().Get("Upload-Offset"), http.StatusConflict)
+	}
+
+	w = patch("6", "world")
+	if w.Code != http.StatusNoContent || w.Header().Get("Upload-Offset") != "11" {
+		t.Fatalf("último PATCH: status %d, Upload-Offset %q", w.Code, w.Header().Get("Upload-Offset"))
+	}
+	if got := string(store.files["docs/nota.txt"]); got != "hello world" {
+		t.Errorf("arquivo montado = %q, esperado %q", got, "hello world")
+	}
+	if want := md5.Sum([]byte("hello world")); !bytes.Equal(store.contentMD5["docs/nota.txt"], want[:]) {
+		t.Errorf("MD5 informado = %x, esperado %x", store.contentMD5["docs/nota.txt"], want)
+	}
+
+	// O estado é removido depois da montagem
+	w = tusRequest(h, http.MethodHead, location, "", nil)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("HEAD após a montagem: status %d, esperado %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestTusUnsupportedVersion(t *testing.T) {
+	h := NewFileHandlers(func(r *http.Request) (storage.BlobStore, error) { return newChunkedMemStore(), nil })
+
+	for _, version := range []string{"", "0.2.2"} {
+		r := httptest.NewRequest(http.MethodPost, "/uploads", nil)
+		if version != "" {
+			r.Header.Set("Tus-Resumable", version)
+		}
+		r.Header.Set("Upload-Length", "1")
+		w := httptest.NewRecorder()
+		h.TusHandler(w, r)
+
+		if w.Code != http.StatusPreconditionFailed {
+			t.Errorf("Tus-Resumable %q: status %d, esperado %d", version, w.Code, http.StatusPreconditionFailed)
+		}
+		if got := w.Header().Get("Tus-Version"); got != tusVersion {
+			t.Errorf("Tus-Resumable %q: Tus-Version = %q, esperado %q", version, got, tusVersion)
+		}
+	}
+}
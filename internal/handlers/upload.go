@@ -1,51 +1,102 @@
 package handlers
 
 import (
-	"fileblobs/pkg/azure"
+	"errors"
 	"io"
+	"log"
 	"net/http"
+	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
-func UploadHandler(w http.ResponseWriter, r *http.Request) {
-	err := r.ParseMultipartForm(32 << 20) // 32MB
+// defaultUploadMaxSizeMB é o limite padrão de um upload quando UPLOAD_MAX_SIZE_MB não é definido
+const defaultUploadMaxSizeMB = 10 * 1024
+
+// UploadHandler lê o corpo multipart como stream: cada arquivo é enviado ao
+// armazenamento à medida que chega, sem ser carregado em memória ou em disco.
+// O campo "prefix" precisa vir antes dos arquivos no formulário (ou na URL).
+func (h *FileHandlers) UploadHandler(w http.ResponseWriter, r *http.Request) {
+	maxBytes := uploadMaxBytes()
+	if r.ContentLength > maxBytes {
+		http.Error(w, "Upload excede o tamanho máximo permitido", http.StatusRequestEntityTooLarge)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+
+	reader, err := r.MultipartReader()
 	if err != nil {
 		http.Error(w, "Erro ao ler arquivos", http.StatusBadRequest)
 		return
 	}
 
-	prefix := r.FormValue("prefix")
-	if prefix != "" && !strings.HasSuffix(prefix, "/") {
-		prefix += "/"
+	store, ok := h.store(w, r)
+	if !ok {
+		return
 	}
 
-	files := r.MultipartForm.File["files"]
-	fileMap := make(map[string][]byte)
+	prefix := normalizeUploadPrefix(r.URL.Query().Get("prefix"))
 
-	for _, f := range files {
-		src, err := f.Open()
-		if err != nil {
-			continue
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
 		}
-		defer src.Close()
-
-		data, err := io.ReadAll(src)
 		if err != nil {
-			continue
+			respondWithUploadError(w, err)
+			return
 		}
 
-		filename := filepath.Base(f.Filename)
-		fileMap[filename] = data
-	}
+		switch part.FormName() {
+		case "prefix":
+			value, err := io.ReadAll(io.LimitReader(part, 4096))
+			if err != nil {
+				respondWithUploadError(w, err)
+				return
+			}
+			prefix = normalizeUploadPrefix(string(value))
 
-	if len(fileMap) > 0 {
-		err = azure.UploadMultipleBlobs(prefix, fileMap)
-		if err != nil {
-			http.Error(w, "Erro ao fazer upload dos arquivos", http.StatusInternalServerError)
-			return
+		case "files":
+			if part.FileName() == "" {
+				break
+			}
+
+			filename := filepath.Base(part.FileName())
+			err = store.Put(r.Context(), prefix+filename, part)
+			if err != nil {
+				log.Printf("Erro ao fazer upload de %s: %v", filename, err)
+				respondWithUploadError(w, err)
+				return
+			}
 		}
+		part.Close()
 	}
 
 	http.Redirect(w, r, "/?prefix="+prefix, http.StatusSeeOther)
 }
+
+func normalizeUploadPrefix(prefix string) string {
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return prefix
+}
+
+// uploadMaxBytes retorna o tamanho máximo de um upload, configurável por UPLOAD_MAX_SIZE_MB
+func uploadMaxBytes() int64 {
+	sizeMB, err := strconv.ParseInt(os.Getenv("UPLOAD_MAX_SIZE_MB"), 10, 64)
+	if err != nil || sizeMB <= 0 {
+		sizeMB = defaultUploadMaxSizeMB
+	}
+	return sizeMB << 20
+}
+
+func respondWithUploadError(w http.ResponseWriter, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		http.Error(w, "Upload excede o tamanho máximo permitido", http.StatusRequestEntityTooLarge)
+		return
+	}
+	http.Error(w, "Erro ao fazer upload dos arquivos", http.StatusInternalServerError)
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"errors"
+	"fileblobs/pkg/storage"
+	"html/template"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// VersionsData alimenta a página de versões de um arquivo
+type VersionsData struct {
+	Path       string
+	Name       string
+	FolderURL  string
+	Versions   []storage.Version
+	IsAdmin    bool   // Exibe a ação de restaurar
+	RestoreURL string // Destino do formulário de restauração, na mesma conta
+	Restored   bool
+}
+
+var versionsTmpl = template.Must(template.New("versions.html").Funcs(template.FuncMap{
+	"formatSize":      formatSize,
+	"formatTimestamp": formatTimestamp,
+}).ParseFiles(templateFile("versions.html")))
+
+// VersionsHandler exibe as versões e os snapshots de um arquivo, com links
+// para baixar cada um e, para administradores, a opção de restaurá-los
+func (h *FileHandlers) VersionsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, r, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	blobPath := storage.CleanPath(r.URL.Query().Get("path"))
+	if blobPath == "" {
+		respondWithError(w, r, "Caminho do arquivo não especificado", http.StatusBadRequest)
+		return
+	}
+
+	versioner, ok := h.versioner(w, r)
+	if !ok {
+		return
+	}
+
+	versions, err := versioner.Versions(r.Context(), blobPath)
+	if err != nil {
+		respondWithStorageError(w, r, blobPath, err)
+		return
+	}
+
+	versionsTmpl.Execute(w, VersionsData{
+		Path:       blobPath,
+		Name:       baseName(blobPath),
+		FolderURL:  folderURL(blobPath),
+		Versions:   versions,
+		IsAdmin:    isAdminRequest(r),
+		RestoreURL: accountURL(r, "/restore-version", nil),
+		Restored:   r.URL.Query().Get("restored") == "1",
+	})
+}
+
+// VersionDownloadHandler baixa uma versão ou um snapshot de um arquivo,
+// identificado por "version" e, nos snapshots, "snapshot=1"
+func (h *FileHandlers) VersionDownloadHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, r, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	blobPath := r.URL.Query().Get("path")
+	version := versionFromRequest(r)
+	if blobPath == "" || version.ID == "" {
+		respondWithError(w, r, "Arquivo ou versão não especificados", http.StatusBadRequest)
+		return
+	}
+
+	versioner, ok := h.versioner(w, r)
+	if !ok {
+		return
+	}
+
+	body, info, err := versioner.OpenVersion(r.Context(), blobPath, version)
+	if err != nil {
+		respondWithStorageError(w, r, blobPath, err)
+		return
+	}
+	defer body.Close()
+
+	setDownloadHeaders(w, info)
+	w.Header().Set("Content-Length", strconv.FormatInt(info.Size, 10))
+	if _, err := io.Copy(w, body); err != nil {
+		log.Printf("Erro ao enviar versão %s de %s: %v", version.ID, blobPath, err)
+	}
+}
+
+// RestoreVersionHandler torna uma versão ou um snapshot o conteúdo atual do
+// arquivo, copiando-o sobre ele. Restrito a administradores.
+func (h *FileHandlers) RestoreVersionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, r, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !isAdminRequest(r) {
+		respondWithError(w, r, "Acesso negado. Apenas administradores podem restaurar versões.", http.StatusForbidden)
+		return
+	}
+
+	blobPath := storage.CleanPath(r.FormValue("path"))
+	version := versionFromRequest(r)
+	if blobPath == "" || version.ID == "" {
+		respondWithError(w, r, "Arquivo ou versão não especificados", http.StatusBadRequest)
+		return
+	}
+
+	versioner, ok := h.versioner(w, r)
+	if !ok {
+		return
+	}
+
+	if err := versioner.RestoreVersion(r.Context(), blobPath, version); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			respondWithError(w, r, "Versão não encontrada", http.StatusNotFound)
+			return
+		}
+		log.Printf("Erro ao restaurar versão %s de %s: %v", version.ID, blobPath, err)
+		respondWithError(w, r, "Erro ao restaurar versão", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Versão %s de %s restaurada", version.ID, blobPath)
+	http.Redirect(w, r, accountURL(r, "/versions", url.Values{"restored": {"1"}, "path": {blobPath}}), http.StatusSeeOther)
+}
+
+// versioner retorna o armazenamento da conta se ele guardar versões dos arquivos
+func (h *FileHandlers) versioner(w http.ResponseWriter, r *http.Request) (storage.Versioner, bool) {
+	store, ok := h.store(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	versioner, ok := store.(storage.Versioner)
+	if !ok {
+		respondWithError(w, r, "O armazenamento desta conta não guarda versões dos arquivos", http.StatusNotImplemented)
+		return nil, false
+	}
+	return versioner, true
+}
+
+// formatTimestamp exibe a data com os segundos, que distinguem versões criadas
+// no mesmo minuto
+func formatTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format("02/01/2006 15:04:05")
+}
+
+func versionFromRequest(r *http.Request) storage.Version {
+	return storage.Version{
+		ID:       r.FormValue("version"),
+		Snapshot: r.FormValue("snapshot") == "1",
+	}
+}
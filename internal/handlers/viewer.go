@@ -0,0 +1,433 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fileblobs/pkg/storage"
+	"fileblobs/pkg/viewer"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	// viewWindow é o máximo lido do arquivo por página de CSV ou JSON
+	viewWindow = 1 << 20
+	// textViewWindow é o tamanho de uma página de texto
+	textViewWindow = 64 << 10
+	// csvHeaderWindow é o trecho do início do CSV lido nas páginas seguintes
+	// para exibir o cabeçalho e detectar o separador
+	csvHeaderWindow = 64 << 10
+
+	utf8BOM = "\xef\xbb\xbf"
+)
+
+// viewCSP permite apenas os estilos da página; o visualizador não usa scripts
+// e todo o conteúdo do arquivo é escapado pelo template
+const viewCSP = "default-src 'none'; style-src 'self' https://cdn.jsdelivr.net; form-action 'self'; frame-ancestors 'self'"
+
+// viewRowCounts são as quantidades de registros por página; a primeira é o padrão
+var viewRowCounts = []int{100, 500, 1000}
+
+// CSVDelimiter é um separador que pode ser escolhido no visualizador
+type CSVDelimiter struct {
+	Param string
+	Label string
+	Rune  rune
+}
+
+var csvDelimiters = []CSVDelimiter{
+	{",", "Vírgula", ','},
+	{";", "Ponto e vírgula", ';'},
+	{"tab", "Tabulação", '\t'},
+	{"|", "Barra vertical", '|'},
+}
+
+// ViewerData alimenta o template do visualizador
+type ViewerData struct {
+	Path string
+	Name string
+	Kind string // "csv", "json" ou "text"
+
+	// Trecho do arquivo exibido, em bytes
+	Size  int64
+	Start int64
+	End   int64
+
+	RowCount     int
+	RowCountURLs []PageSizeLink
+
+	// CSV
+	Header            []string
+	Rows              [][]string
+	FirstRow          int
+	Delimiter         string
+	DelimiterDetected bool
+	Delimiters        []CSVDelimiter
+
+	// JSON
+	Values []*viewer.Node
+
+	// Texto
+	Lines     []string
+	FirstLine int
+
+	Notice string
+	Error  string
+
+	Page     int
+	FirstURL string
+	PrevURL  string
+	NextURL  string
+}
+
+var viewTmpl = template.Must(template.New("viewer.html").Funcs(template.FuncMap{
+	"formatSize": formatSize,
+	"rowNumber": func(first, i int) int {
+		return first + i + 1
+	},
+}).ParseFiles(templateFile("viewer.html")))
+
+// viewCursor é a posição em que uma página começa: o byte no arquivo, a
+// quantidade de registros, valores ou linhas anteriores e, no JSON, se a
+// leitura está dentro do array de nível superior
+type viewCursor struct {
+	Offset  int64
+	Index   int
+	InArray bool
+}
+
+func (c viewCursor) String() string {
+	if c == (viewCursor{}) {
+		return ""
+	}
+	s := strconv.FormatInt(c.Offset, 10) + ":" + strconv.Itoa(c.Index)
+	if c.InArray {
+		s += ":a"
+	}
+	return s
+}
+
+func parseViewCursor(s string) (viewCursor, error) {
+	var c viewCursor
+	if s == "" {
+		return c, nil
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 || (len(parts) == 3 && parts[2] != "a") {
+		return c, fmt.Errorf("posição inválida: %s", s)
+	}
+	offset, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || offset < 0 {
+		return c, fmt.Errorf("posição inválida: %s", s)
+	}
+	index, err := strconv.Atoi(parts[1])
+	if err != nil || index < 0 {
+		return c, fmt.Errorf("posição inválida: %s", s)
+	}
+	return viewCursor{Offset: offset, Index: index, InArray: len(parts) == 3}, nil
+}
+
+// ViewHandler exibe arquivos CSV, JSON e de texto em páginas, lendo do
+// armazenamento apenas a faixa de cada página, de modo que arquivos de vários
+// gigabytes podem ser consultados sem serem baixados. O CSV vira uma tabela
+// com o separador detectado, o JSON uma árvore que pode ser recolhida e o
+// texto uma lista de linhas numeradas. A posição de cada página vem no
+// parâmetro "cursor", e "prev" guarda as das páginas anteriores.
+func (h *FileHandlers) ViewHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		respondWithError(w, r, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	blobPath := r.URL.Query().Get("path")
+	if blobPath == "" {
+		respondWithError(w, r, "Caminho do arquivo não especificado", http.StatusBadRequest)
+		return
+	}
+
+	cursor, err := parseViewCursor(r.URL.Query().Get("cursor"))
+	if err != nil {
+		respondWithError(w, r, "Posição inválida", http.StatusBadRequest)
+		return
+	}
+
+	rowCount := viewRowCounts[0]
+	if n, err := strconv.Atoi(r.URL.Query().Get("rows")); err == nil {
+		for _, count := range viewRowCounts {
+			if n == count {
+				rowCount = n
+			}
+		}
+	}
+
+	store, ok := h.store(w, r)
+	if !ok {
+		return
+	}
+
+	info, err := store.Stat(r.Context(), blobPath)
+	if err != nil {
+		respondWithStorageError(w, r, blobPath, err)
+		return
+	}
+
+	kind := viewKind(info)
+	if kind == "" {
+		respondWithError(w, r, "Visualização não disponível para este tipo de arquivo", http.StatusUnsupportedMediaType)
+		return
+	}
+	if cursor.Offset > info.Size {
+		respondWithError(w, r, "Posição inválida", http.StatusBadRequest)
+		return
+	}
+
+	window := int64(viewWindow)
+	if kind == "text" {
+		window = textViewWindow
+	}
+	content, eof, err := readWindow(r.Context(), store, info, cursor.Offset, window)
+	if err != nil {
+		respondWithStorageError(w, r, blobPath, err)
+		return
+	}
+
+	// skipped conta os bytes do início da janela que não são conteúdo, como o BOM
+	skipped := 0
+	if cursor.Offset == 0 && bytes.HasPrefix(content, []byte(utf8BOM)) {
+		skipped = len(utf8BOM)
+	}
+	content = content[skipped:]
+
+	data := ViewerData{
+		Path:     info.Name,
+		Name:     baseName(info.Name),
+		Kind:     kind,
+		Size:     info.Size,
+		Start:    cursor.Offset,
+		RowCount: rowCount,
+	}
+
+	next := cursor
+	var consumed int
+	switch kind {
+	case "csv":
+		consumed, err = viewCSV(r, store, info, &data, content, eof, cursor)
+		if err != nil {
+			respondWithStorageError(w, r, blobPath, err)
+			return
+		}
+		next.Index += len(data.Rows)
+	case "json":
+		consumed, next = viewJSON(&data, content, eof, cursor)
+	default:
+		data.Lines, consumed = viewer.Text(content, eof)
+		data.FirstLine = cursor.Index + 1
+		next.Index += len(data.Lines)
+	}
+	if consumed > 0 {
+		consumed += skipped
+	}
+	next.Offset = cursor.Offset + int64(consumed)
+	data.End = next.Offset
+
+	if kind != "text" {
+		for _, count := range viewRowCounts {
+			data.RowCountURLs = append(data.RowCountURLs, PageSizeLink{
+				Size: count,
+				URL:  viewURL(r, url.Values{"rows": {strconv.Itoa(count)}, "cursor": nil, "prev": nil, "page": nil}),
+			})
+		}
+	}
+
+	// Como na listagem, "prev" guarda só as últimas páginas e "page" o número
+	// da página atual
+	prev := r.URL.Query()["prev"]
+	if len(prev) > maxPrevMarkers {
+		prev = prev[len(prev)-maxPrevMarkers:]
+	}
+	data.Page, _ = strconv.Atoi(r.URL.Query().Get("page"))
+	if data.Page < len(prev)+1 {
+		data.Page = len(prev) + 1
+	}
+	if data.Page > 1 {
+		data.FirstURL = viewURL(r, url.Values{"cursor": nil, "prev": nil, "page": nil})
+	}
+	if len(prev) > 0 {
+		data.PrevURL = viewURL(r, url.Values{
+			"cursor": {prev[len(prev)-1]},
+			"prev":   prev[:len(prev)-1],
+			"page":   {strconv.Itoa(data.Page - 1)},
+		})
+	}
+	if consumed > 0 && data.Error == "" && next.Offset < info.Size {
+		nextPrev := append(prev[:len(prev):len(prev)], cursor.String())
+		if len(nextPrev) > maxPrevMarkers {
+			nextPrev = nextPrev[len(nextPrev)-maxPrevMarkers:]
+		}
+		data.NextURL = viewURL(r, url.Values{
+			"cursor": {next.String()},
+			"prev":   nextPrev,
+			"page":   {strconv.Itoa(data.Page + 1)},
+		})
+	}
+
+	w.Header().Set("Content-Security-Policy", viewCSP)
+	w.Header().Set("X-Frame-Options", "SAMEORIGIN")
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	viewTmpl.Execute(w, data)
+}
+
+// viewCSV preenche a tabela de uma página de CSV. O cabeçalho é o primeiro
+// registro do arquivo; nas páginas seguintes ele é lido de novo do início.
+func viewCSV(r *http.Request, store storage.BlobStore, info storage.FileInfo, data *ViewerData, content []byte, eof bool, cursor viewCursor) (int, error) {
+	sample, sampleEOF := content, eof
+	if cursor.Offset > 0 {
+		head, headEOF, err := readWindow(r.Context(), store, info, 0, csvHeaderWindow)
+		if err != nil {
+			return 0, err
+		}
+		sample, sampleEOF = bytes.TrimPrefix(head, []byte(utf8BOM)), headEOF
+	}
+
+	delimiter := csvDelimiters[0]
+	found := false
+	for _, d := range csvDelimiters {
+		if d.Param == r.URL.Query().Get("sep") {
+			delimiter, found = d, true
+		}
+	}
+	if !found {
+		detected := viewer.DetectDelimiter(sample)
+		for _, d := range csvDelimiters {
+			if d.Rune == detected {
+				delimiter = d
+			}
+		}
+		data.DelimiterDetected = true
+	}
+	data.Delimiter = delimiter.Param
+	data.Delimiters = csvDelimiters
+	data.FirstRow = cursor.Index
+
+	count := data.RowCount
+	if cursor.Offset == 0 {
+		count++
+	} else if header, _, _ := viewer.CSV(sample, delimiter.Rune, 1, sampleEOF); len(header) > 0 {
+		data.Header = header[0]
+	}
+
+	rows, consumed, err := viewer.CSV(content, delimiter.Rune, count, eof)
+	if cursor.Offset == 0 && len(rows) > 0 {
+		data.Header, rows = rows[0], rows[1:]
+	}
+	data.Rows = rows
+
+	switch {
+	case err != nil:
+		data.Error = "Erro ao ler o CSV: " + err.Error()
+	case consumed == 0 && !eof:
+		data.Notice = fmt.Sprintf("O próximo registro é maior que o limite de leitura de %s. Baixe o arquivo para vê-lo.", formatSize(viewWindow))
+	}
+	return consumed, nil
+}
+
+// viewJSON preenche os valores de uma página de JSON. Um arquivo que é um
+// único array tem os elementos paginados como valores independentes.
+func viewJSON(data *ViewerData, content []byte, eof bool, cursor viewCursor) (int, viewCursor) {
+	next := cursor
+	skipped := 0
+	inArray := cursor.InArray
+	if cursor.Offset == 0 {
+		if start := viewer.ArrayStart(content); start >= 0 {
+			skipped, inArray = start, true
+		}
+	}
+
+	page, err := viewer.JSON(content[skipped:], inArray, data.RowCount, eof)
+	if err != nil {
+		data.Error = "JSON inválido: " + err.Error()
+	}
+	if page.Partial {
+		data.Notice = fmt.Sprintf("O valor é maior que o limite de leitura de %s e foi exibido em parte. Baixe o arquivo para vê-lo inteiro.", formatSize(viewWindow))
+	}
+
+	// Um documento único dispensa numeração
+	if inArray || len(page.Values) > 1 || cursor.Index > 0 {
+		for i, value := range page.Values {
+			value.Label = strconv.Itoa(cursor.Index + i)
+		}
+	}
+	data.Values = page.Values
+
+	next.Index += len(page.Values)
+	next.InArray = page.InArray
+	// O valor exibido em parte não pode ser continuado na próxima página
+	if page.Partial || page.Consumed == 0 {
+		return 0, next
+	}
+	return skipped + page.Consumed, next
+}
+
+// readWindow lê até size bytes do arquivo a partir de offset e informa se a
+// leitura chegou ao fim do arquivo
+func readWindow(ctx context.Context, store storage.BlobStore, info storage.FileInfo, offset, size int64) ([]byte, bool, error) {
+	count := min(size, info.Size-offset)
+	if count <= 0 {
+		return nil, true, nil
+	}
+
+	body, err := store.OpenRange(ctx, info.Name, offset, count)
+	if err != nil {
+		return nil, false, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(body, count))
+	if err != nil {
+		return nil, false, err
+	}
+	return data, offset+int64(len(data)) >= info.Size, nil
+}
+
+// viewKind retorna como o arquivo é exibido no visualizador, ou "" se não puder ser
+func viewKind(info storage.FileInfo) string {
+	// Extensões que o registro de tipos MIME do sistema pode não conhecer
+	switch strings.ToLower(path.Ext(info.Name)) {
+	case ".csv", ".tsv":
+		return "csv"
+	case ".json", ".jsonl", ".ndjson":
+		return "json"
+	}
+
+	switch mediaType := storedMediaType(info); {
+	case mediaType == "text/csv", mediaType == "text/tab-separated-values":
+		return "csv"
+	case mediaType == "application/json", mediaType == "application/x-ndjson":
+		return "json"
+	case strings.HasPrefix(mediaType, "text/"):
+		return "text"
+	}
+	return ""
+}
+
+// canView indica se o arquivo pode ser aberto em /view
+func canView(info storage.FileInfo) bool {
+	return viewKind(info) != ""
+}
+
+// viewURL monta o endereço do visualizador mantendo os parâmetros atuais
+func viewURL(r *http.Request, changes url.Values) string {
+	query := changeQuery(r, changes)
+	if query.Get("cursor") == "" {
+		query.Del("cursor")
+	}
+	return "/view?" + query.Encode()
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"fileblobs/pkg/storage"
+	"fmt"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseViewCursor(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    viewCursor
+		wantErr bool
+	}{
+		{in: "", want: viewCursor{}},
+		{in: "0:0", want: viewCursor{}},
+		{in: "1048576:100", want: viewCursor{Offset: 1048576, Index: 100}},
+		{in: "12:3:a", want: viewCursor{Offset: 12, Index: 3, InArray: true}},
+		{in: "12", wantErr: true},
+		{in: "12:", wantErr: true},
+		{in: ":3", wantErr: true},
+		{in: "x:3", wantErr: true},
+		{in: "12:y", wantErr: true},
+		{in: "-1:0", wantErr: true},
+		{in: "0:-1", wantErr: true},
+		{in: "12:3:b", wantErr: true},
+		{in: "12:3:a:a", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseViewCursor(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseViewCursor(%q) = %+v, esperado erro", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseViewCursor(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseViewCursor(%q) = %+v, esperado %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestViewCursorRoundTrip(t *testing.T) {
+	for _, cursor := range []viewCursor{
+		{},
+		{Offset: 1, Index: 0},
+		{Offset: 5 << 30, Index: 123456},
+		{Offset: 7, Index: 2, InArray: true},
+	} {
+		got, err := parseViewCursor(cursor.String())
+		if err != nil || got != cursor {
+			t.Errorf("parseViewCursor(%q) = %+v, %v; esperado %+v", cursor.String(), got, err, cursor)
+		}
+	}
+}
+
+func TestViewHandlerBoundsPrev(t *testing.T) {
+	// 30 páginas de 100 registros
+	var csv strings.Builder
+	csv.WriteString("id,nome\n")
+	for i := range 3000 {
+		fmt.Fprintf(&csv, "%d,item %d\n", i, i)
+	}
+	store := newMemStore(map[string]string{"dados.csv": csv.String()})
+	h := NewFileHandlers(func(r *http.Request) (storage.BlobStore, error) { return store, nil })
+
+	target := "/view?path=dados.csv"
+	for page := 1; page <= 25; page++ {
+		w := httptest.NewRecorder()
+		h.ViewHandler(w, httptest.NewRequest("GET", target, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("página %d: status %d", page, w.Code)
+		}
+
+		body := w.Body.String()
+		if !strings.Contains(body, fmt.Sprintf("Página %d<", page)) {
+			t.Fatalf("página %d não exibida como tal", page)
+		}
+		if page > 1 && !strings.Contains(body, fmt.Sprintf(">item %d<", (page-1)*100)) {
+			t.Fatalf("página %d não começa no registro %d", page, (page-1)*100)
+		}
+
+		next := regexp.MustCompile(`href="(/view\?[^"]*)"[^>]*>Próxima`).FindStringSubmatch(body)
+		if next == nil {
+			t.Fatalf("página %d sem link para a próxima", page)
+		}
+		target = html.UnescapeString(next[1])
+		u, _ := url.Parse(target)
+		if n := len(u.Query()["prev"]); n > maxPrevMarkers {
+			t.Fatalf("link da página %d com %d entradas em prev, máximo %d", page+1, n, maxPrevMarkers)
+		}
+		if got := u.Query().Get("page"); got != strconv.Itoa(page+1) {
+			t.Fatalf("link da página %d com page=%s", page+1, got)
+		}
+	}
+}
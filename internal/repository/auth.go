@@ -2,6 +2,7 @@ package repository
 
 import (
 	"encoding/json"
+	"fileblobs/pkg/storage"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -13,14 +14,51 @@ type User struct {
 	Username string `json:"username"`
 	Password string `json:"password"` // In a real application, store hashed passwords
 	IsAdmin  bool   `json:"isAdmin"`  // Indica se o usuário é administrador
+	CanWrite bool   `json:"canWrite"` // Permite alterar arquivos (excluir, mover) sem ser administrador
 }
 
 type StorageAccount struct {
 	Name          string `json:"name"`
 	Description   string `json:"description"`
+	Type          string `json:"type,omitempty"` // Tipo de armazenamento; vazio equivale a "azure"
 	AccountName   string `json:"accountName"`
 	AccountKey    string `json:"accountKey"`
 	ContainerName string `json:"containerName"`
+	RootPath      string `json:"rootPath,omitempty"` // Diretório raiz das contas do tipo "filesystem"
+
+	// Campos das contas do tipo "s3"
+	Endpoint        string `json:"endpoint,omitempty"`
+	Region          string `json:"region,omitempty"`
+	AccessKeyID     string `json:"accessKeyId,omitempty"`
+	SecretAccessKey string `json:"secretAccessKey,omitempty"`
+	Bucket          string `json:"bucket,omitempty"`
+	PathStyle       bool   `json:"pathStyle,omitempty"`
+}
+
+// StorageType retorna o tipo de armazenamento da conta, considerando Azure como padrão
+func (a StorageAccount) StorageType() string {
+	if a.Type == "" {
+		return storage.TypeAzure
+	}
+	return a.Type
+}
+
+// StorageConfig converte a conta na configuração usada para abrir o armazenamento
+func (a StorageAccount) StorageConfig() storage.Config {
+	return storage.Config{
+		Type:          a.StorageType(),
+		AccountName:   a.AccountName,
+		AccountKey:    a.AccountKey,
+		ContainerName: a.ContainerName,
+		RootPath:      a.RootPath,
+
+		Endpoint:        a.Endpoint,
+		Region:          a.Region,
+		AccessKeyID:     a.AccessKeyID,
+		SecretAccessKey: a.SecretAccessKey,
+		Bucket:          a.Bucket,
+		PathStyle:       a.PathStyle,
+	}
 }
 
 type AuthData struct {
@@ -41,6 +79,9 @@ var (
 const dataDir = "./data"
 const authFile = "auth.json"
 
+// DefaultAccountName é o nome da conta criada a partir das variáveis de ambiente
+const DefaultAccountName = "Conta Padrão"
+
 func initAuthData() {
 	// Create data directory if it doesn't exist
 	if _, err := os.Stat(dataDir); os.IsNotExist(err) {
@@ -66,11 +107,20 @@ func initAuthData() {
 			},
 			StorageAccounts: []StorageAccount{
 				{
-					Name:          "Conta Padrão",
+					Name:          DefaultAccountName,
 					Description:   "Conta de armazenamento padrão",
+					Type:          os.Getenv("STORAGE_TYPE"),
 					AccountName:   os.Getenv("AZURE_STORAGE_ACCOUNT_NAME"),
 					AccountKey:    os.Getenv("AZURE_STORAGE_ACCOUNT_KEY"),
 					ContainerName: os.Getenv("AZURE_STORAGE_CONTAINER"),
+					RootPath:      os.Getenv("STORAGE_ROOT_PATH"),
+
+					Endpoint:        os.Getenv("S3_ENDPOINT"),
+					Region:          os.Getenv("S3_REGION"),
+					AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+					SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+					Bucket:          os.Getenv("S3_BUCKET"),
+					PathStyle:       os.Getenv("S3_PATH_STYLE") == "true",
 				},
 			},
 		}
@@ -151,6 +201,20 @@ func IsUserAdmin(username string) bool {
 	return false
 }
 
+// CanUserWrite verifica se um usuário pode alterar arquivos; administradores sempre podem
+func CanUserWrite(username string) bool {
+	authDataOnce.Do(initAuthData)
+	authMutex.RLock()
+	defer authMutex.RUnlock()
+
+	for _, user := range authData.Users {
+		if user.Username == username && (user.IsAdmin || user.CanWrite) {
+			return true
+		}
+	}
+	return false
+}
+
 func GetStorageAccounts() []StorageAccount {
 	authDataOnce.Do(initAuthData)
 
@@ -258,7 +322,7 @@ func UpdateStorageAccount(originalName string, updatedAccount StorageAccount) er
 	defer authMutex.Unlock()
 
 	// Don't allow updating the default account
-	if originalName == "Conta Padrão" {
+	if originalName == DefaultAccountName {
 		return fmt.Errorf("não é permitido editar a conta padrão")
 	}
 
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Upload guarda o estado de um upload retomável entre as requisições
+type Upload struct {
+	ID            string    `json:"id"`
+	Owner         string    `json:"owner"`   // Usuário que iniciou o upload
+	Account       string    `json:"account"` // Conta de armazenamento de destino
+	Path          string    `json:"path"`
+	Length        int64     `json:"length"`
+	Offset        int64     `json:"offset"`                // Bytes já gravados no armazenamento
+	Chunks        int       `json:"chunks"`                // Partes já enviadas ao armazenamento
+	StoreUploadID string    `json:"storeUploadId"`         // Identificador do upload no backend
+	ContentType   string    `json:"contentType,omitempty"` // Detectado na primeira parte
+	MD5State      []byte    `json:"md5State,omitempty"`    // MD5 parcial das partes já gravadas
+	Committed     bool      `json:"committed,omitempty"`   // Arquivo já montado no armazenamento
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// UploadRetention é o tempo que um upload incompleto pode ser retomado.
+// Acompanha o prazo em que o Azure descarta blocos não confirmados.
+const UploadRetention = 7 * 24 * time.Hour
+
+const uploadsFile = "uploads.json"
+
+var (
+	uploads      map[string]Upload
+	uploadsOnce  sync.Once
+	uploadsMutex sync.Mutex
+)
+
+func initUploads() {
+	uploads = make(map[string]Upload)
+
+	data, err := os.ReadFile(filepath.Join(dataDir, uploadsFile))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Printf("Erro ao ler arquivo de uploads: %v\n", err)
+		}
+		return
+	}
+
+	var list []Upload
+	if err := json.Unmarshal(data, &list); err != nil {
+		fmt.Printf("Erro ao analisar arquivo de uploads: %v\n", err)
+		return
+	}
+
+	for _, upload := range list {
+		if time.Since(upload.CreatedAt) < UploadRetention {
+			uploads[upload.ID] = upload
+		}
+	}
+}
+
+// saveUploads grava os uploads em andamento; deve ser chamada com uploadsMutex bloqueado
+func saveUploads() error {
+	list := make([]Upload, 0, len(uploads))
+	for _, upload := range uploads {
+		list = append(list, upload)
+	}
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("erro ao serializar uploads: %w", err)
+	}
+
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return fmt.Errorf("erro ao criar diretório de dados: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dataDir, uploadsFile), data, 0644); err != nil {
+		return fmt.Errorf("erro ao salvar arquivo de uploads: %w", err)
+	}
+	return nil
+}
+
+// GetUpload retorna um upload em andamento que ainda não expirou
+func GetUpload(id string) (Upload, bool) {
+	uploadsOnce.Do(initUploads)
+	uploadsMutex.Lock()
+	defer uploadsMutex.Unlock()
+
+	upload, found := uploads[id]
+	if !found || time.Since(upload.CreatedAt) >= UploadRetention {
+		return Upload{}, false
+	}
+	return upload, true
+}
+
+// SaveUpload cria ou atualiza o estado de um upload
+func SaveUpload(upload Upload) error {
+	uploadsOnce.Do(initUploads)
+	uploadsMutex.Lock()
+	defer uploadsMutex.Unlock()
+
+	uploads[upload.ID] = upload
+	return saveUploads()
+}
+
+// DeleteUpload remove o estado de um upload concluído ou cancelado
+func DeleteUpload(id string) error {
+	uploadsOnce.Do(initUploads)
+	uploadsMutex.Lock()
+	defer uploadsMutex.Unlock()
+
+	delete(uploads, id)
+	return saveUploads()
+}
@@ -0,0 +1,48 @@
+package azure
+
+import (
+	"bytes"
+	"context"
+	"fileblobs/pkg/storage"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/streaming"
+)
+
+var _ storage.ChunkedUploader = (*Store)(nil)
+
+// BeginChunks apenas gera o prefixo dos IDs de bloco: no Azure cada parte
+// é um bloco não confirmado do próprio blob, sem estado extra no servidor
+func (s *Store) BeginChunks(ctx context.Context, path string) (string, error) {
+	return newUploadID()
+}
+
+func (s *Store) StageChunk(ctx context.Context, path, uploadID string, index int, data []byte) error {
+	blobClient := s.client.NewBlockBlobClient(storage.CleanPath(path))
+
+	_, err := blobClient.StageBlock(ctx, blockID(uploadID, index), streaming.NopCloser(bytes.NewReader(data)), nil)
+	if err != nil {
+		return fmt.Errorf("erro ao enviar bloco %d: %w", index, err)
+	}
+	return nil
+}
+
+func (s *Store) CommitChunks(ctx context.Context, path, uploadID string, count int, contentType string, contentMD5 []byte) error {
+	blobClient := s.client.NewBlockBlobClient(storage.CleanPath(path))
+
+	blockIDs := make([]string, count)
+	for i := range blockIDs {
+		blockIDs[i] = blockID(uploadID, i)
+	}
+
+	if _, err := blobClient.CommitBlockList(ctx, blockIDs, commitOptions(contentType, contentMD5)); err != nil {
+		return fmt.Errorf("erro ao confirmar blocos do blob: %w", err)
+	}
+	return nil
+}
+
+// AbortChunks não precisa fazer nada: o Azure descarta sozinho os blocos
+// não confirmados após uma semana
+func (s *Store) AbortChunks(ctx context.Context, path, uploadID string) error {
+	return nil
+}
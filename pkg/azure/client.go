@@ -2,7 +2,7 @@ package azure
 
 import (
 	"fmt"
-	"os"
+	"strings"
 	"sync"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
@@ -10,61 +10,84 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/service"
 )
 
+// clientKey identifica um cliente do pool
+type clientKey struct {
+	account   string
+	endpoint  string
+	container string
+}
+
+// pooledClient guarda o cliente junto da chave de acesso usada para criá-lo,
+// para que uma troca de chave gere um novo cliente
+type pooledClient struct {
+	client     *container.Client
+	accountKey string
+}
+
 var (
-	clientMutex  sync.RWMutex
-	clientCache  *container.Client
-	clientCached bool
+	clientMutex sync.RWMutex
+	clientPool  = make(map[clientKey]*pooledClient)
 )
 
-// ResetClient clears the client cache to force creating a new client with updated credentials
-func ResetClient() {
-	clientMutex.Lock()
-	defer clientMutex.Unlock()
-	clientCache = nil
-	clientCached = false
-}
+// GetAzureBlobClient retorna o cliente do container a partir do pool, criando-o
+// na primeira vez. Os clientes são reutilizados entre requisições e usuários.
+// Um endpoint vazio usa o endereço público padrão da conta.
+func GetAzureBlobClient(account, accountKey, endpoint, containerName string) (*container.Client, error) {
+	key := clientKey{account: account, endpoint: endpoint, container: containerName}
 
-func GetAzureBlobClient() (*container.Client, error) {
 	clientMutex.RLock()
-	if clientCached && clientCache != nil {
-		defer clientMutex.RUnlock()
-		return clientCache, nil
-	}
+	entry, ok := clientPool[key]
 	clientMutex.RUnlock()
+	if ok && entry.accountKey == accountKey {
+		return entry.client, nil
+	}
 
 	clientMutex.Lock()
 	defer clientMutex.Unlock()
 
 	// Check again after acquiring the write lock
-	if clientCached && clientCache != nil {
-		return clientCache, nil
+	if entry, ok := clientPool[key]; ok && entry.accountKey == accountKey {
+		return entry.client, nil
+	}
+
+	client, err := newContainerClient(account, accountKey, endpoint, containerName)
+	if err != nil {
+		return nil, err
 	}
-	containerName := os.Getenv("AZURE_STORAGE_CONTAINER")
-	account := os.Getenv("AZURE_STORAGE_ACCOUNT_NAME")
-	key := os.Getenv("AZURE_STORAGE_ACCOUNT_KEY")
 
-	// Se alguma das variáveis estiver vazia, apenas retorna um cliente nulo
+	clientPool[key] = &pooledClient{client: client, accountKey: accountKey}
+	return client, nil
+}
+
+// EvictClient remove do pool o cliente de um container, por exemplo após a
+// edição da conta, forçando a criação de um novo cliente no próximo uso
+func EvictClient(account, endpoint, containerName string) {
+	clientMutex.Lock()
+	defer clientMutex.Unlock()
+	delete(clientPool, clientKey{account: account, endpoint: endpoint, container: containerName})
+}
+
+func newContainerClient(account, accountKey, endpoint, containerName string) (*container.Client, error) {
+	// Se alguma das credenciais estiver vazia, apenas retorna um cliente nulo
 	// Isso permite a navegação na UI mesmo sem configuração completa
-	if containerName == "" || account == "" || key == "" {
-		return nil, fmt.Errorf("variáveis de ambiente ausentes")
+	if containerName == "" || account == "" || accountKey == "" {
+		return nil, fmt.Errorf("credenciais da conta ausentes")
 	}
 
-	cred, err := azblob.NewSharedKeyCredential(account, key)
+	cred, err := azblob.NewSharedKeyCredential(account, accountKey)
 	if err != nil {
 		return nil, fmt.Errorf("erro criando credencial: %w", err)
 	}
 
 	serviceURL := fmt.Sprintf("https://%s.blob.core.windows.net/", account)
+	if endpoint != "" {
+		serviceURL = strings.TrimSuffix(endpoint, "/") + "/"
+	}
+
 	serviceClient, err := service.NewClientWithSharedKeyCredential(serviceURL, cred, nil)
 	if err != nil {
 		return nil, fmt.Errorf("erro criando service client: %w", err)
 	}
 
-	containerClient := serviceClient.NewContainerClient(containerName)
-
-	// Cache the client
-	clientCache = containerClient
-	clientCached = true
-
-	return containerClient, nil
+	return serviceClient.NewContainerClient(containerName), nil
 }
@@ -0,0 +1,79 @@
+package azure
+
+import (
+	"context"
+	"fileblobs/pkg/storage"
+	"fmt"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/sas"
+)
+
+// copyPollInterval é o intervalo entre as verificações de status de uma cópia
+const copyPollInterval = 500 * time.Millisecond
+
+// sasClockSkew antecipa o início da validade das SAS para tolerar relógios dessincronizados
+const sasClockSkew = 5 * time.Minute
+
+var (
+	_ storage.URLSigner    = (*Store)(nil)
+	_ storage.RemoteCopier = (*Store)(nil)
+)
+
+// Copy faz uma cópia no servidor (StartCopyFromURL) e aguarda sua conclusão
+func (s *Store) Copy(ctx context.Context, src, dst string) error {
+	srcClient := s.client.NewBlobClient(storage.CleanPath(src))
+	return s.copyFromURL(ctx, srcClient.URL(), dst, src)
+}
+
+// CopyFromURL copia para este container um blob de outra conta ou container.
+// A URL de origem precisa ser legível pelo Azure, em geral uma URL com SAS.
+func (s *Store) CopyFromURL(ctx context.Context, srcURL, dst string) error {
+	return s.copyFromURL(ctx, srcURL, dst, dst)
+}
+
+// SignedURL gera uma URL com SAS de leitura para o blob
+func (s *Store) SignedURL(ctx context.Context, path string, expiry time.Duration) (string, error) {
+	blobClient := s.client.NewBlobClient(storage.CleanPath(path))
+
+	start := time.Now().UTC().Add(-sasClockSkew)
+	url, err := blobClient.GetSASURL(sas.BlobPermissions{Read: true}, time.Now().UTC().Add(expiry), &blob.GetSASURLOptions{
+		StartTime: &start,
+	})
+	if err != nil {
+		return "", fmt.Errorf("erro ao gerar SAS de %s: %w", path, err)
+	}
+	return url, nil
+}
+
+// copyFromURL inicia a cópia e consulta o status até que ela termine;
+// name identifica o arquivo nas mensagens de erro
+func (s *Store) copyFromURL(ctx context.Context, srcURL, dst, name string) error {
+	dstClient := s.client.NewBlobClient(storage.CleanPath(dst))
+
+	resp, err := dstClient.StartCopyFromURL(ctx, srcURL, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar cópia de %s: %w", name, wrapNotFound(err, name))
+	}
+
+	status := resp.CopyStatus
+	for status != nil && *status == blob.CopyStatusTypePending {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(copyPollInterval):
+		}
+
+		props, err := dstClient.GetProperties(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("erro ao verificar cópia de %s: %w", name, err)
+		}
+		status = props.CopyStatus
+	}
+
+	if status != nil && *status != blob.CopyStatusTypeSuccess {
+		return fmt.Errorf("cópia de %s terminou com status %s", name, *status)
+	}
+	return nil
+}
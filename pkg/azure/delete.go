@@ -0,0 +1,17 @@
+package azure
+
+import (
+	"context"
+	"fileblobs/pkg/storage"
+	"fmt"
+)
+
+func (s *Store) Delete(ctx context.Context, path string) error {
+	normalizedPath := storage.CleanPath(path)
+
+	_, err := s.client.NewBlobClient(normalizedPath).Delete(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao excluir blob: %w", wrapNotFound(err, normalizedPath))
+	}
+	return nil
+}
@@ -2,38 +2,98 @@ package azure
 
 import (
 	"context"
+	"fileblobs/pkg/storage"
 	"fmt"
 	"io"
-	"path/filepath"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
 )
 
-func DownloadBlob(blobPath string) ([]byte, error) {
-	containerClient, err := GetAzureBlobClient()
+func (s *Store) Stat(ctx context.Context, path string) (storage.FileInfo, error) {
+	normalizedPath := storage.CleanPath(path)
+
+	props, err := s.client.NewBlobClient(normalizedPath).GetProperties(ctx, nil)
 	if err != nil {
-		return nil, err
-	}
-	// Normalizar o caminho do blob removendo barras iniciais
-	// para evitar caminhos como "container//path"
-	normalizedPath := blobPath
-	for len(normalizedPath) > 0 && normalizedPath[0] == '/' {
-		normalizedPath = normalizedPath[1:]
+		return storage.FileInfo{}, fmt.Errorf("erro ao obter propriedades do blob: %w", wrapNotFound(err, normalizedPath))
 	}
 
-	// Substituir barra invertida por barra normal (importante para Windows)
-	normalizedPath = filepath.ToSlash(normalizedPath)
+	info := storage.FileInfo{Name: normalizedPath}
+	if props.ContentLength != nil {
+		info.Size = *props.ContentLength
+	}
+	if props.LastModified != nil {
+		info.LastModified = *props.LastModified
+	}
+	if props.ContentType != nil {
+		info.ContentType = *props.ContentType
+	}
+	if props.ETag != nil {
+		info.ETag = string(*props.ETag)
+	}
+	if props.AccessTier != nil {
+		info.AccessTier = *props.AccessTier
+	}
+	if props.ArchiveStatus != nil {
+		info.ArchiveStatus = *props.ArchiveStatus
+	}
+	if props.RehydratePriority != nil {
+		info.RehydratePriority = *props.RehydratePriority
+	}
+	info.ContentMD5 = props.ContentMD5
+	return info, nil
+}
 
-	blobClient := containerClient.NewBlobClient(normalizedPath)
+func (s *Store) Open(ctx context.Context, path string) (io.ReadCloser, storage.FileInfo, error) {
+	// Normalizar o caminho do blob para evitar caminhos como "container//path"
+	normalizedPath := storage.CleanPath(path)
 
-	resp, err := blobClient.DownloadStream(context.Background(), nil)
+	resp, err := s.client.NewBlobClient(normalizedPath).DownloadStream(ctx, nil)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao baixar blob: %w", err)
+		return nil, storage.FileInfo{}, fmt.Errorf("erro ao baixar blob: %w", wrapNotFound(err, normalizedPath))
 	}
-	defer resp.Body.Close()
 
-	data, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("erro lendo blob: %w", err)
+	return resp.Body, fileInfoFromDownload(normalizedPath, resp), nil
+}
+
+func fileInfoFromDownload(name string, resp blob.DownloadStreamResponse) storage.FileInfo {
+	info := storage.FileInfo{Name: name}
+	if resp.ContentLength != nil {
+		info.Size = *resp.ContentLength
+	}
+	if resp.LastModified != nil {
+		info.LastModified = *resp.LastModified
+	}
+	if resp.ContentType != nil {
+		info.ContentType = *resp.ContentType
 	}
+	if resp.ETag != nil {
+		info.ETag = string(*resp.ETag)
+	}
+	return info
+}
+
+func (s *Store) OpenRange(ctx context.Context, path string, offset, count int64) (io.ReadCloser, error) {
+	return s.openRange(ctx, path, offset, count, nil)
+}
+
+// OpenRangeIfMatch lê a faixa apenas se o blob ainda tiver o ETag informado
+func (s *Store) OpenRangeIfMatch(ctx context.Context, path string, offset, count int64, etag string) (io.ReadCloser, error) {
+	ifMatch := azcore.ETag(etag)
+	return s.openRange(ctx, path, offset, count, &blob.AccessConditions{
+		ModifiedAccessConditions: &blob.ModifiedAccessConditions{IfMatch: &ifMatch},
+	})
+}
 
-	return data, nil
+func (s *Store) openRange(ctx context.Context, path string, offset, count int64, conditions *blob.AccessConditions) (io.ReadCloser, error) {
+	normalizedPath := storage.CleanPath(path)
+
+	resp, err := s.client.NewBlobClient(normalizedPath).DownloadStream(ctx, &blob.DownloadStreamOptions{
+		Range:            blob.HTTPRange{Offset: offset, Count: count},
+		AccessConditions: conditions,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao baixar faixa do blob: %w", wrapNotFound(err, normalizedPath))
+	}
+	return resp.Body, nil
 }
@@ -4,9 +4,9 @@ import (
 	"archive/zip"
 	"bytes"
 	"context"
+	"fileblobs/pkg/storage"
 	"io"
 	"os"
-	"regexp"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
 )
@@ -20,11 +20,7 @@ func DownloadFolderAsZip(connectionString, containerName, folderPath string) ([]
 	containerClient := client.ServiceClient().NewContainerClient(containerName)
 
 	// Regex para filtrar blobs
-	pattern := "^" + regexp.QuoteMeta(folderPath)
-	pattern = regexp.MustCompile(`\\\*`).ReplaceAllString(pattern, "[^/]+")
-	pattern = regexp.MustCompile(`\\\/`).ReplaceAllString(pattern, "/")
-	pattern += "(/|$)"
-	regex, err := regexp.Compile("(?i)" + pattern)
+	regex, err := storage.GlobRegexp(folderPath)
 	if err != nil {
 		return nil, err
 	}
@@ -2,114 +2,145 @@ package azure
 
 import (
 	"context"
+	"fileblobs/pkg/storage"
 	"fmt"
-	"os"
 	"strings"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
-	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/service"
 )
 
-func ListFoldersAndFiles(prefix string) (folders []string, files []string, err error) {
-	account := os.Getenv("AZURE_STORAGE_ACCOUNT_NAME")
-	key := os.Getenv("AZURE_STORAGE_ACCOUNT_KEY")
-	containerName := os.Getenv("AZURE_STORAGE_CONTAINER")
-	if account == "" || key == "" || containerName == "" {
-		return nil, nil, fmt.Errorf("variáveis de ambiente ausentes")
+func (s *Store) List(ctx context.Context, prefix string, opts storage.ListOptions) (storage.Listing, error) {
+	if opts.Recursive {
+		files, err := s.ListBlobsFromFolder(ctx, prefix)
+		return storage.Listing{Files: files}, err
 	}
 
-	// Normalizar o prefixo removendo barras iniciais
-	normalizedPrefix := prefix
-	for len(normalizedPrefix) > 0 && normalizedPrefix[0] == '/' {
-		normalizedPrefix = normalizedPrefix[1:]
-	}
-
-	cred, err := azblob.NewSharedKeyCredential(account, key)
-	if err != nil {
-		return nil, nil, fmt.Errorf("erro criando credencial: %w", err)
-	}
+	return s.ListFoldersAndFiles(ctx, prefix, opts)
+}
 
-	serviceURL := fmt.Sprintf("https://%s.blob.core.windows.net/", account)
-	serviceClient, err := service.NewClientWithSharedKeyCredential(serviceURL, cred, nil)
-	if err != nil {
-		return nil, nil, fmt.Errorf("erro criando service client: %w", err)
-	}
-	containerClient := serviceClient.NewContainerClient(containerName)
+// ListFoldersAndFiles lista um nível da hierarquia. Com opts.MaxResults, busca
+// uma única página do Azure a partir de opts.Marker e devolve o marcador de
+// continuação em NextMarker, sem percorrer a pasta inteira.
+func (s *Store) ListFoldersAndFiles(ctx context.Context, prefix string, opts storage.ListOptions) (storage.Listing, error) {
+	var listing storage.Listing
 
-	// Converter barras invertidas em barras normais (importante para Windows)
-	normalizedPrefix = strings.ReplaceAll(normalizedPrefix, "\\", "/")
+	// Normalizar o prefixo removendo barras iniciais e convertendo barras invertidas
+	normalizedPrefix := storage.CleanPath(prefix)
 
-	pager := containerClient.NewListBlobsHierarchyPager("/", &container.ListBlobsHierarchyOptions{
+	options := &container.ListBlobsHierarchyOptions{
 		Prefix: &normalizedPrefix,
-	})
+	}
+	if opts.Marker != "" {
+		options.Marker = &opts.Marker
+	}
+	if opts.MaxResults > 0 {
+		maxResults := int32(min(opts.MaxResults, maxPageSize))
+		options.MaxResults = &maxResults
+	}
+
+	pager := s.client.NewListBlobsHierarchyPager("/", options)
 
 	for pager.More() {
-		page, err := pager.NextPage(context.Background())
+		page, err := pager.NextPage(ctx)
 		if err != nil {
-			return nil, nil, fmt.Errorf("erro ao paginar blobs: %w", err)
+			return storage.Listing{}, fmt.Errorf("erro ao paginar blobs: %w", err)
 		}
 
 		for _, p := range page.Segment.BlobPrefixes {
 			if p.Name != nil {
-				folders = append(folders, strings.TrimSuffix(*p.Name, "/"))
+				listing.Folders = append(listing.Folders, strings.TrimSuffix(*p.Name, "/"))
 			}
 		}
 
 		for _, blob := range page.Segment.BlobItems {
-			if blob.Name != nil && !strings.HasSuffix(*blob.Name, "/") {
-				name := strings.TrimPrefix(*blob.Name, prefix)
+			if blob.Name != nil && !storage.IsFolderMarker(*blob.Name) {
+				name := strings.TrimPrefix(*blob.Name, normalizedPrefix)
 				if !strings.Contains(name, "/") {
-					files = append(files, name)
+					listing.Files = append(listing.Files, fileInfoFromItem(blob))
 				}
 			}
 		}
+
+		if opts.MaxResults > 0 {
+			if page.NextMarker != nil {
+				listing.NextMarker = *page.NextMarker
+			}
+			break
+		}
 	}
 
-	return folders, files, nil
+	return listing, nil
 }
 
-// Lista todos os arquivos recursivamente dentro de uma pasta (para gerar ZIP)
-func ListBlobsFromFolder(prefix string) ([]string, error) {
-	var files []string
-
-	account := os.Getenv("AZURE_STORAGE_ACCOUNT_NAME")
-	key := os.Getenv("AZURE_STORAGE_ACCOUNT_KEY")
-	containerName := os.Getenv("AZURE_STORAGE_CONTAINER")
-
-	if account == "" || key == "" || containerName == "" {
-		return nil, fmt.Errorf("variáveis de ambiente ausentes")
-	}
-
-	cred, err := azblob.NewSharedKeyCredential(account, key)
-	if err != nil {
-		return nil, fmt.Errorf("erro criando credencial: %w", err)
-	}
+// Lista todos os arquivos recursivamente dentro de uma pasta (para gerar ZIP),
+// incluindo os marcadores de pastas vazias
+func (s *Store) ListBlobsFromFolder(ctx context.Context, prefix string) ([]storage.FileInfo, error) {
+	var files []storage.FileInfo
 
-	serviceURL := fmt.Sprintf("https://%s.blob.core.windows.net/", account)
-	serviceClient, err := service.NewClientWithSharedKeyCredential(serviceURL, cred, nil)
+	err := s.Walk(ctx, prefix, func(file storage.FileInfo) error {
+		files = append(files, file)
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("erro criando service client: %w", err)
+		return nil, err
 	}
 
-	containerClient := serviceClient.NewContainerClient(containerName)
+	return files, nil
+}
 
-	pager := containerClient.NewListBlobsFlatPager(&container.ListBlobsFlatOptions{
-		Prefix: &prefix,
+// Walk percorre a listagem plana do Azure página por página
+func (s *Store) Walk(ctx context.Context, prefix string, fn storage.WalkFunc) error {
+	normalizedPrefix := storage.CleanPath(prefix)
+	pager := s.client.NewListBlobsFlatPager(&container.ListBlobsFlatOptions{
+		Prefix: &normalizedPrefix,
 	})
 
 	for pager.More() {
-		page, err := pager.NextPage(context.Background())
+		page, err := pager.NextPage(ctx)
 		if err != nil {
-			return nil, fmt.Errorf("erro listando blobs: %w", err)
+			return fmt.Errorf("erro listando blobs: %w", err)
 		}
 
 		for _, blob := range page.Segment.BlobItems {
-			if blob.Name != nil && !strings.HasSuffix(*blob.Name, "/") {
-				files = append(files, *blob.Name)
+			if blob.Name != nil {
+				if err := fn(fileInfoFromItem(blob)); err != nil {
+					return err
+				}
 			}
 		}
 	}
 
-	return files, nil
+	return nil
+}
+
+// maxPageSize é o limite do Azure para MaxResults em uma listagem
+const maxPageSize = 5000
+
+func fileInfoFromItem(item *container.BlobItem) storage.FileInfo {
+	info := storage.FileInfo{Name: *item.Name}
+	if p := item.Properties; p != nil {
+		if p.ContentLength != nil {
+			info.Size = *p.ContentLength
+		}
+		if p.LastModified != nil {
+			info.LastModified = *p.LastModified
+		}
+		if p.ContentType != nil {
+			info.ContentType = *p.ContentType
+		}
+		if p.ETag != nil {
+			info.ETag = string(*p.ETag)
+		}
+		if p.AccessTier != nil {
+			info.AccessTier = string(*p.AccessTier)
+		}
+		if p.ArchiveStatus != nil {
+			info.ArchiveStatus = string(*p.ArchiveStatus)
+		}
+		if p.RehydratePriority != nil {
+			info.RehydratePriority = string(*p.RehydratePriority)
+		}
+		info.ContentMD5 = p.ContentMD5
+	}
+	return info
 }
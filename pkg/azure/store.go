@@ -0,0 +1,57 @@
+package azure
+
+import (
+	"fileblobs/pkg/storage"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+)
+
+// Store implementa storage.BlobStore sobre um container do Azure Blob Storage
+type Store struct {
+	client *container.Client
+}
+
+var (
+	_ storage.BlobStore              = (*Store)(nil)
+	_ storage.ConditionalRangeOpener = (*Store)(nil)
+)
+
+// NewStore cria um Store a partir de um cliente de container já configurado
+func NewStore(client *container.Client) *Store {
+	return &Store{client: client}
+}
+
+func init() {
+	storage.Register(storage.TypeAzure, openStore)
+	storage.RegisterEvictor(storage.TypeAzure, func(cfg storage.Config) {
+		EvictClient(cfg.AccountName, cfg.Endpoint, cfg.ContainerName)
+	})
+}
+
+// openStore cria um Store para a conta e o container da configuração,
+// reaproveitando o cliente do pool
+func openStore(cfg storage.Config) (storage.BlobStore, error) {
+	client, err := GetAzureBlobClient(cfg.AccountName, cfg.AccountKey, cfg.Endpoint, cfg.ContainerName)
+	if err != nil {
+		return nil, err
+	}
+	return NewStore(client), nil
+}
+
+// wrapNotFound converte o erro de blob inexistente do Azure em storage.ErrNotFound
+// e os de blob arquivado ou em reidratação nos erros correspondentes de storage
+func wrapNotFound(err error, path string) error {
+	switch {
+	case bloberror.HasCode(err, bloberror.BlobNotFound, bloberror.ContainerNotFound):
+		return fmt.Errorf("%s: %w", path, storage.ErrNotFound)
+	case bloberror.HasCode(err, bloberror.BlobArchived):
+		return fmt.Errorf("%s: %w", path, storage.ErrArchived)
+	case bloberror.HasCode(err, bloberror.BlobBeingRehydrated):
+		return fmt.Errorf("%s: %w", path, storage.ErrRehydrating)
+	case bloberror.HasCode(err, bloberror.ConditionNotMet):
+		return fmt.Errorf("%s: %w", path, storage.ErrModified)
+	}
+	return err
+}
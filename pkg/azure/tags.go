@@ -0,0 +1,80 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fileblobs/pkg/storage"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+)
+
+var _ storage.Tagger = (*Store)(nil)
+
+func (s *Store) Tags(ctx context.Context, path string) (map[string]string, error) {
+	path = storage.CleanPath(path)
+
+	resp, err := s.client.NewBlobClient(path).GetTags(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao obter tags de %s: %w", path, wrapNotFound(err, path))
+	}
+
+	tags := make(map[string]string, len(resp.BlobTagSet))
+	for _, tag := range resp.BlobTagSet {
+		if tag.Key != nil && tag.Value != nil {
+			tags[*tag.Key] = *tag.Value
+		}
+	}
+	return tags, nil
+}
+
+func (s *Store) SetTags(ctx context.Context, path string, tags map[string]string) error {
+	path = storage.CleanPath(path)
+
+	if _, err := s.client.NewBlobClient(path).SetTags(ctx, tags, nil); err != nil {
+		return fmt.Errorf("erro ao gravar tags de %s: %w", path, wrapNotFound(err, path))
+	}
+	return nil
+}
+
+// FindByTags usa Find Blobs by Tags no container. O Azure devolve com cada
+// blob apenas as tags citadas na expressão.
+func (s *Store) FindByTags(ctx context.Context, where string, fn storage.TaggedWalkFunc) error {
+	var marker *string
+	for {
+		resp, err := s.client.FilterBlobs(ctx, where, &container.FilterBlobsOptions{Marker: marker})
+		if err != nil {
+			if bloberror.HasCode(err, bloberror.InvalidQueryParameterValue, bloberror.InvalidInput) {
+				return fmt.Errorf("%w: %v", storage.ErrInvalidTagQuery, err)
+			}
+			return fmt.Errorf("erro ao buscar blobs por tags: %w", err)
+		}
+
+		for _, item := range resp.Blobs {
+			if item.Name == nil {
+				continue
+			}
+			file := storage.TaggedFile{Name: *item.Name, Tags: map[string]string{}}
+			if item.Tags != nil {
+				for _, tag := range item.Tags.BlobTagSet {
+					if tag.Key != nil && tag.Value != nil {
+						file.Tags[*tag.Key] = *tag.Value
+					}
+				}
+			}
+
+			if err := fn(file); err != nil {
+				if errors.Is(err, storage.SkipAll) {
+					return nil
+				}
+				return err
+			}
+		}
+
+		if resp.NextMarker == nil || *resp.NextMarker == "" {
+			return nil
+		}
+		marker = resp.NextMarker
+	}
+}
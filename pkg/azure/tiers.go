@@ -0,0 +1,26 @@
+package azure
+
+import (
+	"context"
+	"fileblobs/pkg/storage"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+)
+
+var _ storage.Tierer = (*Store)(nil)
+
+func (s *Store) SetTier(ctx context.Context, path, tier, priority string) error {
+	path = storage.CleanPath(path)
+
+	var options *blob.SetTierOptions
+	if priority != "" {
+		rehydratePriority := blob.RehydratePriority(priority)
+		options = &blob.SetTierOptions{RehydratePriority: &rehydratePriority}
+	}
+
+	if _, err := s.client.NewBlobClient(path).SetTier(ctx, blob.AccessTier(tier), options); err != nil {
+		return fmt.Errorf("erro ao alterar a camada de %s para %s: %w", path, tier, wrapNotFound(err, path))
+	}
+	return nil
+}
@@ -1,47 +1,69 @@
 package azure
 
 import (
+	"bytes"
 	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fileblobs/pkg/storage"
 	"fmt"
-	"os"
-	"path/filepath"
+	"io"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
-	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/service"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/streaming"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blockblob"
 )
 
-func UploadBlob(path string, data []byte) error {
-	account := os.Getenv("AZURE_STORAGE_ACCOUNT_NAME")
-	key := os.Getenv("AZURE_STORAGE_ACCOUNT_KEY")
-	containerName := os.Getenv("AZURE_STORAGE_CONTAINER")
+// blockSize é o tamanho de cada bloco enviado com StageBlock.
+// A memória usada por upload fica limitada a um bloco.
+const blockSize = 8 << 20
 
-	if account == "" || key == "" || containerName == "" {
-		return fmt.Errorf("variáveis de ambiente ausentes")
-	}
+// Put envia o conteúdo em blocos (StageBlock) à medida que ele é lido e
+// confirma o blob ao final com CommitBlockList, sem carregar o arquivo inteiro.
+// O MD5 é calculado durante a leitura, já que o Azure não o calcula para blobs
+// montados a partir de blocos.
+func (s *Store) Put(ctx context.Context, path string, body io.Reader) error {
+	blobClient := s.client.NewBlockBlobClient(storage.CleanPath(path))
 
-	cred, err := azblob.NewSharedKeyCredential(account, key)
+	// Um prefixo aleatório evita conflito com blocos de outro upload simultâneo do mesmo blob
+	uploadID, err := newUploadID()
 	if err != nil {
-		return fmt.Errorf("erro criando credencial: %w", err)
+		return err
 	}
 
-	serviceURL := fmt.Sprintf("https://%s.blob.core.windows.net/", account)
-	serviceClient, err := service.NewClientWithSharedKeyCredential(serviceURL, cred, nil)
-	if err != nil {
-		return fmt.Errorf("erro criando service client: %w", err)
-	}
-	containerClient := serviceClient.NewContainerClient(containerName)
-	// Normalizar o caminho do blob removendo barras iniciais
-	normalizedPath := path
-	for len(normalizedPath) > 0 && normalizedPath[0] == '/' {
-		normalizedPath = normalizedPath[1:]
-	}
+	buf := make([]byte, blockSize)
+	hash := md5.New()
+	var blockIDs []string
+	contentType := ""
 
-	// Substituir barra invertida por barra normal (importante para Windows)
-	normalizedPath = filepath.ToSlash(normalizedPath)
+	for {
+		n, readErr := io.ReadFull(body, buf)
+		if contentType == "" {
+			// O tipo é detectado pelo início do primeiro bloco
+			contentType = storage.DetectContentType(path, buf[:n])
+		}
+		if n > 0 {
+			hash.Write(buf[:n])
+			blockID := blockID(uploadID, len(blockIDs))
+			_, err := blobClient.StageBlock(ctx, blockID, streaming.NopCloser(bytes.NewReader(buf[:n])), nil)
+			if err != nil {
+				return fmt.Errorf("erro ao enviar bloco %d: %w", len(blockIDs), err)
+			}
+			blockIDs = append(blockIDs, blockID)
+		}
 
-	blobClient := containerClient.NewBlockBlobClient(normalizedPath)
+		if errors.Is(readErr, io.EOF) || errors.Is(readErr, io.ErrUnexpectedEOF) {
+			break
+		}
+		if readErr != nil {
+			return fmt.Errorf("erro lendo conteúdo para upload: %w", readErr)
+		}
+	}
 
-	_, err = blobClient.UploadBuffer(context.Background(), data, nil)
+	_, err = blobClient.CommitBlockList(ctx, blockIDs, commitOptions(contentType, hash.Sum(nil)))
 	if err != nil {
 		return fmt.Errorf("erro ao fazer upload do blob: %w", err)
 	}
@@ -49,46 +71,25 @@ func UploadBlob(path string, data []byte) error {
 	return nil
 }
 
-func UploadMultipleBlobs(prefix string, files map[string][]byte) error {
-	account := os.Getenv("AZURE_STORAGE_ACCOUNT_NAME")
-	key := os.Getenv("AZURE_STORAGE_ACCOUNT_KEY")
-	containerName := os.Getenv("AZURE_STORAGE_CONTAINER")
-
-	if account == "" || key == "" || containerName == "" {
-		return fmt.Errorf("variáveis de ambiente ausentes")
-	}
-
-	cred, err := azblob.NewSharedKeyCredential(account, key)
-	if err != nil {
-		return fmt.Errorf("erro criando credencial: %w", err)
+// commitOptions grava o tipo e o MD5 do conteúdo nos cabeçalhos HTTP do blob,
+// que o Azure devolve nos downloads e nas propriedades (Content-MD5)
+func commitOptions(contentType string, contentMD5 []byte) *blockblob.CommitBlockListOptions {
+	return &blockblob.CommitBlockListOptions{
+		HTTPHeaders: &blob.HTTPHeaders{BlobContentType: &contentType, BlobContentMD5: contentMD5},
 	}
+}
 
-	serviceURL := fmt.Sprintf("https://%s.blob.core.windows.net/", account)
-	serviceClient, err := service.NewClientWithSharedKeyCredential(serviceURL, cred, nil)
-	if err != nil {
-		return fmt.Errorf("erro criando service client: %w", err)
-	}
-	containerClient := serviceClient.NewContainerClient(containerName)
-
-	for filename, content := range files {
-		path := filepath.Join(prefix, filename)
-
-		// Normalizar o caminho do blob removendo barras iniciais
-		normalizedPath := path
-		for len(normalizedPath) > 0 && normalizedPath[0] == '/' {
-			normalizedPath = normalizedPath[1:]
-		}
-
-		// Substituir barra invertida por barra normal (importante para Windows)
-		normalizedPath = filepath.ToSlash(normalizedPath)
-
-		blobClient := containerClient.NewBlockBlobClient(normalizedPath)
-
-		_, err := blobClient.UploadBuffer(context.Background(), content, nil)
-		if err != nil {
-			return fmt.Errorf("erro ao fazer upload de %s: %w", filename, err)
-		}
+// newUploadID gera um identificador aleatório para os blocos de um upload
+func newUploadID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("erro gerando identificador de upload: %w", err)
 	}
+	return hex.EncodeToString(b), nil
+}
 
-	return nil
+// blockID monta o identificador de um bloco. Todos os IDs de um blob
+// precisam ter o mesmo tamanho, por isso o índice tem largura fixa.
+func blockID(uploadID string, index int) string {
+	return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s-%08d", uploadID, index)))
 }
@@ -0,0 +1,129 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fileblobs/pkg/storage"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+)
+
+var _ storage.Versioner = (*Store)(nil)
+
+// Versions lista as versões (com o versionamento de blobs ativo na conta) e os
+// snapshots do blob. Sem versionamento, o blob atual aparece como uma versão sem ID.
+func (s *Store) Versions(ctx context.Context, path string) ([]storage.Version, error) {
+	path = storage.CleanPath(path)
+	pager := s.client.NewListBlobsFlatPager(&container.ListBlobsFlatOptions{
+		Prefix:  &path,
+		Include: container.ListBlobsInclude{Versions: true, Snapshots: true},
+	})
+
+	var versions []storage.Version
+	done := false
+	for !done && pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao listar versões de %s: %w", path, err)
+		}
+
+		for _, item := range page.Segment.BlobItems {
+			if item.Name == nil {
+				continue
+			}
+			// A listagem é ordenada por nome; depois do arquivo vêm apenas outros blobs
+			if *item.Name != path {
+				done = true
+				break
+			}
+			versions = append(versions, versionFromItem(item))
+		}
+	}
+
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("%s: %w", path, storage.ErrNotFound)
+	}
+	storage.SortVersions(versions)
+	return versions, nil
+}
+
+func (s *Store) OpenVersion(ctx context.Context, path string, version storage.Version) (io.ReadCloser, storage.FileInfo, error) {
+	path = storage.CleanPath(path)
+
+	client, err := s.versionClient(path, version)
+	if err != nil {
+		return nil, storage.FileInfo{}, err
+	}
+
+	resp, err := client.DownloadStream(ctx, nil)
+	if err != nil {
+		return nil, storage.FileInfo{}, fmt.Errorf("erro ao baixar versão do blob: %w", wrapNotFound(err, path))
+	}
+	return resp.Body, fileInfoFromDownload(path, resp), nil
+}
+
+// RestoreVersion promove a versão ou o snapshot copiando-o sobre o blob base,
+// como faz o portal do Azure. Com versionamento, o conteúdo substituído
+// continua disponível como uma nova versão anterior.
+func (s *Store) RestoreVersion(ctx context.Context, path string, version storage.Version) error {
+	if version.ID == "" {
+		return errors.New("a versão atual não precisa ser restaurada")
+	}
+
+	client, err := s.versionClient(path, version)
+	if err != nil {
+		return err
+	}
+	return s.copyFromURL(ctx, client.URL(), path, path)
+}
+
+// versionClient retorna o cliente do blob apontando para a versão ou o snapshot
+func (s *Store) versionClient(path string, version storage.Version) (*blob.Client, error) {
+	client := s.client.NewBlobClient(storage.CleanPath(path))
+	switch {
+	case version.ID == "":
+		return client, nil
+	case version.Snapshot:
+		return client.WithSnapshot(version.ID)
+	default:
+		return client.WithVersionID(version.ID)
+	}
+}
+
+func versionFromItem(item *container.BlobItem) storage.Version {
+	var version storage.Version
+	switch {
+	case item.Snapshot != nil && *item.Snapshot != "":
+		version.ID = *item.Snapshot
+		version.Snapshot = true
+	case item.VersionID != nil:
+		version.ID = *item.VersionID
+		version.Current = item.IsCurrentVersion != nil && *item.IsCurrentVersion
+	default:
+		version.Current = true
+	}
+
+	if p := item.Properties; p != nil {
+		if p.ContentLength != nil {
+			version.Size = *p.ContentLength
+		}
+		if p.LastModified != nil {
+			version.LastModified = *p.LastModified
+		}
+		if p.ContentType != nil {
+			version.ContentType = *p.ContentType
+		}
+	}
+
+	// Os IDs de versão e de snapshot são a data de criação, como 2024-05-01T12:00:00.1234567Z
+	if created, err := time.Parse(time.RFC3339Nano, version.ID); err == nil {
+		version.Created = created
+	} else {
+		version.Created = version.LastModified
+	}
+	return version
+}
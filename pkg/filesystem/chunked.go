@@ -0,0 +1,111 @@
+package filesystem
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fileblobs/pkg/storage"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// chunksDir guarda as partes dos uploads em andamento. Como começa com
+// tempPrefix, o diretório nunca aparece nas listagens.
+const chunksDir = tempPrefix + "uploads"
+
+var _ storage.ChunkedUploader = (*Store)(nil)
+
+// BeginChunks cria o diretório onde as partes do upload ficam até a montagem
+func (s *Store) BeginChunks(ctx context.Context, path string) (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("erro gerando identificador de upload: %w", err)
+	}
+	uploadID := hex.EncodeToString(b)
+
+	if err := os.MkdirAll(s.chunkDir(uploadID), 0755); err != nil {
+		return "", fmt.Errorf("erro ao criar diretório do upload: %w", err)
+	}
+	return uploadID, nil
+}
+
+func (s *Store) StageChunk(ctx context.Context, path, uploadID string, index int, data []byte) error {
+	if err := os.WriteFile(s.chunkPath(uploadID, index), data, 0644); err != nil {
+		return fmt.Errorf("erro ao gravar parte %d: %w", index, err)
+	}
+	return nil
+}
+
+// CommitChunks concatena as partes no arquivo final, com a mesma gravação
+// atômica do Put, e remove o diretório do upload. O tipo do conteúdo não é
+// gravado: neste backend ele vem sempre do nome e do próprio arquivo.
+func (s *Store) CommitChunks(ctx context.Context, path, uploadID string, count int, contentType string, contentMD5 []byte) error {
+	body := &chunkReader{store: s, uploadID: uploadID, count: count}
+	err := s.Put(ctx, path, body)
+	body.close()
+	if err != nil {
+		return err
+	}
+
+	return s.AbortChunks(ctx, path, uploadID)
+}
+
+func (s *Store) AbortChunks(ctx context.Context, path, uploadID string) error {
+	if err := os.RemoveAll(s.chunkDir(uploadID)); err != nil {
+		return fmt.Errorf("erro ao remover partes do upload: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) chunkDir(uploadID string) string {
+	// filepath.Base impede que o identificador aponte para fora do diretório de partes
+	return filepath.Join(s.root, chunksDir, filepath.Base(uploadID))
+}
+
+func (s *Store) chunkPath(uploadID string, index int) string {
+	return filepath.Join(s.chunkDir(uploadID), fmt.Sprintf("%08d", index))
+}
+
+// chunkReader lê as partes em sequência, abrindo um arquivo por vez
+type chunkReader struct {
+	store    *Store
+	uploadID string
+	count    int
+	next     int
+	current  *os.File
+}
+
+func (c *chunkReader) Read(p []byte) (int, error) {
+	for {
+		if c.current == nil {
+			if c.next >= c.count {
+				return 0, io.EOF
+			}
+			f, err := os.Open(c.store.chunkPath(c.uploadID, c.next))
+			if err != nil {
+				return 0, fmt.Errorf("parte %d do upload não encontrada: %w", c.next, err)
+			}
+			c.current = f
+			c.next++
+		}
+
+		n, err := c.current.Read(p)
+		if err == io.EOF {
+			c.close()
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (c *chunkReader) close() {
+	if c.current != nil {
+		c.current.Close()
+		c.current = nil
+	}
+}
@@ -0,0 +1,438 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"fileblobs/pkg/storage"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// tempPrefix identifica os arquivos internos do backend, como os temporários
+// criados durante um Put, que nunca aparecem nas listagens
+const tempPrefix = ".fileblobs-"
+
+// folderMarkerFile marca um diretório criado como pasta ("pasta/"), para que ele
+// continue existindo quando o último arquivo sair dele
+const folderMarkerFile = tempPrefix + "folder"
+
+// Store implementa storage.BlobStore sobre um diretório local.
+// As pastas do armazenamento correspondem a diretórios reais no disco.
+type Store struct {
+	root string
+}
+
+var (
+	_ storage.BlobStore              = (*Store)(nil)
+	_ storage.ConditionalRangeOpener = (*Store)(nil)
+)
+
+func init() {
+	storage.Register(storage.TypeFilesystem, func(cfg storage.Config) (storage.BlobStore, error) {
+		return NewStore(cfg.RootPath)
+	})
+}
+
+// NewStore cria um Store com raiz no diretório informado, criando-o se necessário
+func NewStore(root string) (*Store, error) {
+	if root == "" {
+		return nil, fmt.Errorf("diretório raiz não informado")
+	}
+
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao resolver diretório raiz: %w", err)
+	}
+
+	if err := os.MkdirAll(absRoot, 0755); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório raiz: %w", err)
+	}
+
+	return &Store{root: absRoot}, nil
+}
+
+// localPath converte um caminho de blob em um caminho no disco, sempre dentro da raiz
+func (s *Store) localPath(name string) string {
+	// Limpar a partir de "/" impede que ".." escape da raiz
+	cleaned := path.Clean("/" + storage.CleanPath(name))
+	return filepath.Join(s.root, filepath.FromSlash(cleaned))
+}
+
+// splitPrefix separa um prefixo em diretório ("a/b/") e início de nome ("c")
+func splitPrefix(prefix string) (dir, namePrefix string) {
+	prefix = storage.CleanPath(prefix)
+	i := strings.LastIndex(prefix, "/")
+	return prefix[:i+1], prefix[i+1:]
+}
+
+func (s *Store) List(ctx context.Context, prefix string, opts storage.ListOptions) (storage.Listing, error) {
+	if opts.Recursive {
+		return s.listRecursive(ctx, prefix)
+	}
+
+	var listing storage.Listing
+	dir, namePrefix := splitPrefix(prefix)
+
+	entries, err := os.ReadDir(s.localPath(dir))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return listing, nil
+		}
+		return listing, fmt.Errorf("erro ao listar diretório: %w", err)
+	}
+
+	// os.ReadDir devolve as entradas em ordem de nome, então o marcador de
+	// continuação é simplesmente o caminho do último item da página
+	count := 0
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, namePrefix) || strings.HasPrefix(name, tempPrefix) || name == storage.KeepFileName {
+			continue
+		}
+		if opts.Marker != "" && dir+name <= opts.Marker {
+			continue
+		}
+
+		if opts.MaxResults > 0 && count == opts.MaxResults {
+			listing.NextMarker = lastListed(listing)
+			break
+		}
+		count++
+
+		if entry.IsDir() {
+			listing.Folders = append(listing.Folders, dir+name)
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		listing.Files = append(listing.Files, fileInfo(dir+name, info))
+	}
+
+	return listing, nil
+}
+
+// lastListed retorna o caminho do último item incluído em uma listagem por nível
+func lastListed(listing storage.Listing) string {
+	last := ""
+	if n := len(listing.Folders); n > 0 {
+		last = listing.Folders[n-1]
+	}
+	if n := len(listing.Files); n > 0 && listing.Files[n-1].Name > last {
+		last = listing.Files[n-1].Name
+	}
+	return last
+}
+
+func (s *Store) listRecursive(ctx context.Context, prefix string) (storage.Listing, error) {
+	var listing storage.Listing
+
+	err := s.Walk(ctx, prefix, func(file storage.FileInfo) error {
+		listing.Files = append(listing.Files, file)
+		return nil
+	})
+	if err != nil {
+		return storage.Listing{}, err
+	}
+
+	return listing, nil
+}
+
+// Walk percorre os diretórios abaixo do prefixo, entregando cada arquivo à medida que é encontrado
+func (s *Store) Walk(ctx context.Context, prefix string, fn storage.WalkFunc) error {
+	prefix = storage.CleanPath(prefix)
+	dir, _ := splitPrefix(prefix)
+
+	err := filepath.WalkDir(s.localPath(dir), func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if strings.HasPrefix(entry.Name(), tempPrefix) {
+			// Diretórios internos (como as partes de uploads) não são percorridos
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.root, p)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+
+		// Diretórios vazios aparecem como marcadores "pasta/", como no Azure
+		if entry.IsDir() {
+			if rel == "." || !strings.HasPrefix(name+"/", prefix) {
+				return nil
+			}
+			if children, err := os.ReadDir(p); err == nil && (len(children) == 0 || hasFolderMarker(p)) {
+				return fn(storage.FileInfo{Name: name + "/"})
+			}
+			return nil
+		}
+
+		if !strings.HasPrefix(name, prefix) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		return fn(fileInfo(name, info))
+	})
+	if err != nil && !errors.Is(err, storage.SkipAll) {
+		return fmt.Errorf("erro ao listar arquivos: %w", err)
+	}
+
+	return err
+}
+
+func (s *Store) Stat(ctx context.Context, name string) (storage.FileInfo, error) {
+	name = storage.CleanPath(name)
+
+	info, err := os.Stat(s.localPath(name))
+	if err != nil {
+		return storage.FileInfo{}, wrapNotFound(err, name)
+	}
+	if info.IsDir() {
+		if isFolderMarker(name) {
+			return storage.FileInfo{Name: name, LastModified: info.ModTime()}, nil
+		}
+		return storage.FileInfo{}, fmt.Errorf("%s: %w", name, storage.ErrNotFound)
+	}
+	if isFolderMarker(name) {
+		return storage.FileInfo{}, fmt.Errorf("%s: %w", name, storage.ErrNotFound)
+	}
+
+	result := fileInfo(name, info)
+	if result.ContentType == "" {
+		if f, err := os.Open(s.localPath(name)); err == nil {
+			result.ContentType = sniffFile(f, name)
+			f.Close()
+		}
+	}
+	return result, nil
+}
+
+func (s *Store) Open(ctx context.Context, name string) (io.ReadCloser, storage.FileInfo, error) {
+	name = storage.CleanPath(name)
+
+	f, err := os.Open(s.localPath(name))
+	if err != nil {
+		return nil, storage.FileInfo{}, wrapNotFound(err, name)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, storage.FileInfo{}, fmt.Errorf("erro ao ler propriedades de %s: %w", name, err)
+	}
+	if info.IsDir() {
+		f.Close()
+		if isFolderMarker(name) {
+			return io.NopCloser(strings.NewReader("")), storage.FileInfo{Name: name, LastModified: info.ModTime()}, nil
+		}
+		return nil, storage.FileInfo{}, fmt.Errorf("%s: %w", name, storage.ErrNotFound)
+	}
+	if isFolderMarker(name) {
+		f.Close()
+		return nil, storage.FileInfo{}, fmt.Errorf("%s: %w", name, storage.ErrNotFound)
+	}
+
+	result := fileInfo(name, info)
+	if result.ContentType == "" {
+		result.ContentType = sniffFile(f, name)
+	}
+	return f, result, nil
+}
+
+func (s *Store) OpenRange(ctx context.Context, name string, offset, count int64) (io.ReadCloser, error) {
+	return s.openRange(ctx, name, offset, count, "")
+}
+
+// OpenRangeIfMatch lê a faixa apenas se o arquivo ainda tiver o ETag informado
+func (s *Store) OpenRangeIfMatch(ctx context.Context, name string, offset, count int64, etag string) (io.ReadCloser, error) {
+	return s.openRange(ctx, name, offset, count, etag)
+}
+
+func (s *Store) openRange(ctx context.Context, name string, offset, count int64, etag string) (io.ReadCloser, error) {
+	f, info, err := s.Open(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if etag != "" && info.ETag != etag {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", info.Name, storage.ErrModified)
+	}
+
+	file, ok := f.(*os.File)
+	if !ok {
+		// Marcador de pasta: não há conteúdo
+		return f, nil
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("erro ao posicionar leitura: %w", err)
+	}
+
+	if count <= 0 {
+		return file, nil
+	}
+	return &limitedFile{Reader: io.LimitReader(file, count), file: file}, nil
+}
+
+// limitedFile limita a leitura de um arquivo mantendo a possibilidade de fechá-lo
+type limitedFile struct {
+	io.Reader
+	file *os.File
+}
+
+func (l *limitedFile) Close() error {
+	return l.file.Close()
+}
+
+// Put grava o conteúdo em um arquivo temporário e o renomeia ao final,
+// para que leitores concorrentes nunca vejam um arquivo incompleto
+func (s *Store) Put(ctx context.Context, name string, body io.Reader) error {
+	target := s.localPath(name)
+
+	// Um marcador de pasta vira um diretório de verdade, com folderMarkerFile
+	// para distingui-lo dos diretórios criados apenas para guardar arquivos
+	if isFolderMarker(name) {
+		if err := os.MkdirAll(target, 0755); err != nil {
+			return fmt.Errorf("erro ao criar diretório: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(target, folderMarkerFile), nil, 0644); err != nil {
+			return fmt.Errorf("erro ao criar marcador de pasta: %w", err)
+		}
+		_, err := io.Copy(io.Discard, body)
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("erro ao criar diretório: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), tempPrefix+"*.tmp")
+	if err != nil {
+		return fmt.Errorf("erro ao criar arquivo temporário: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return fmt.Errorf("erro ao gravar arquivo: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("erro ao gravar arquivo: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return fmt.Errorf("erro ao salvar arquivo: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) Delete(ctx context.Context, name string) error {
+	name = storage.CleanPath(name)
+
+	target := s.localPath(name)
+
+	// "pasta/" só pode remover um diretório e "pasta" só um arquivo, nunca o
+	// outro de mesmo nome. Como no Azure, excluir o marcador de uma pasta com
+	// arquivos não a remove.
+	info, err := os.Stat(target)
+	if err != nil {
+		return wrapNotFound(err, name)
+	}
+	if info.IsDir() != isFolderMarker(name) {
+		return fmt.Errorf("%s: %w", name, storage.ErrNotFound)
+	}
+	if isFolderMarker(name) {
+		if err := os.Remove(filepath.Join(target, folderMarkerFile)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("erro ao excluir marcador de pasta: %w", err)
+		}
+		if children, err := os.ReadDir(target); err == nil && len(children) > 0 {
+			return nil
+		}
+	}
+
+	if err := os.Remove(target); err != nil {
+		return wrapNotFound(err, name)
+	}
+
+	// Como no Azure, a pasta deixa de existir quando o último arquivo sai dela,
+	// exceto se tiver sido criada como pasta. os.Remove falha em diretórios com
+	// conteúdo, inclusive folderMarkerFile, o que encerra a limpeza.
+	for dir := filepath.Dir(target); dir != s.root && strings.HasPrefix(dir, s.root); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+	return nil
+}
+
+func (s *Store) Copy(ctx context.Context, src, dst string) error {
+	body, _, err := s.Open(ctx, src)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	return s.Put(ctx, dst, body)
+}
+
+// isFolderMarker indica um marcador "pasta/", que neste backend é o próprio diretório
+func isFolderMarker(name string) bool {
+	return strings.HasSuffix(storage.CleanPath(name), "/")
+}
+
+// hasFolderMarker indica se o diretório foi criado como pasta
+func hasFolderMarker(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, folderMarkerFile))
+	return err == nil
+}
+
+// sniffFile detecta o tipo de um arquivo sem extensão conhecida pelos primeiros
+// bytes. Como não há onde guardar o tipo, a listagem mostra apenas o da extensão.
+func sniffFile(f *os.File, name string) string {
+	head := make([]byte, 512)
+	n, _ := f.ReadAt(head, 0)
+	return storage.DetectContentType(name, head[:n])
+}
+
+// fileInfo monta o storage.FileInfo de um arquivo local
+func fileInfo(name string, info fs.FileInfo) storage.FileInfo {
+	return storage.FileInfo{
+		Name:         name,
+		Size:         info.Size(),
+		LastModified: info.ModTime(),
+		ContentType:  mime.TypeByExtension(path.Ext(name)),
+		// O ETag combina data de modificação e tamanho, como fazem os servidores HTTP
+		ETag: fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size()),
+	}
+}
+
+// wrapNotFound converte erros de arquivo inexistente em storage.ErrNotFound
+func wrapNotFound(err error, name string) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%s: %w", name, storage.ErrNotFound)
+	}
+	return err
+}
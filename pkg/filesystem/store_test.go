@@ -0,0 +1,150 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"fileblobs/pkg/storage"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	return store
+}
+
+func put(t *testing.T, store *Store, name string) {
+	t.Helper()
+	if err := store.Put(context.Background(), name, strings.NewReader(name)); err != nil {
+		t.Fatalf("Put(%q): %v", name, err)
+	}
+}
+
+func exists(store *Store, name string) bool {
+	_, err := os.Stat(store.localPath(name))
+	return err == nil
+}
+
+func TestDeleteKeepsCreatedFolders(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	// "nova/" foi criada como pasta; "a/b/" só existe por causa do arquivo
+	put(t, store, "nova/")
+	put(t, store, "nova/sub/arquivo.txt")
+	put(t, store, "a/b/arquivo.txt")
+
+	if err := store.Delete(ctx, "nova/sub/arquivo.txt"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if exists(store, "nova/sub") {
+		t.Error("diretório nova/sub não foi removido")
+	}
+	if !exists(store, "nova") {
+		t.Error("pasta criada nova/ foi removida junto com o último arquivo")
+	}
+
+	if err := store.Delete(ctx, "a/b/arquivo.txt"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if exists(store, "a") {
+		t.Error("diretório a/ sem marcador não foi removido")
+	}
+
+	listing, err := store.List(ctx, "nova/", storage.ListOptions{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(listing.Files) != 0 || len(listing.Folders) != 0 {
+		t.Errorf("listagem de nova/ = %+v, esperado vazia", listing)
+	}
+}
+
+func TestDeleteFolderMarker(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	put(t, store, "nova/")
+	put(t, store, "nova/arquivo.txt")
+
+	// Com arquivos, excluir o marcador mantém a pasta, mas não mais como pasta criada
+	if err := store.Delete(ctx, "nova/"); err != nil {
+		t.Fatalf("Delete do marcador: %v", err)
+	}
+	if !exists(store, "nova/arquivo.txt") {
+		t.Fatal("arquivo da pasta foi removido junto com o marcador")
+	}
+	if exists(store, filepath.Join("nova", folderMarkerFile)) {
+		t.Error("marcador de pasta não foi removido")
+	}
+
+	if err := store.Delete(ctx, "nova/arquivo.txt"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if exists(store, "nova") {
+		t.Error("pasta sem marcador não foi removida com o último arquivo")
+	}
+
+	if err := store.Delete(ctx, "nova/"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("Delete de pasta inexistente = %v, esperado ErrNotFound", err)
+	}
+}
+
+func TestDeleteDirectoryWithoutSlash(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	// Um diretório vazio, como o que sobra de uma pasta criada fora da aplicação
+	if err := os.Mkdir(store.localPath("vazia"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	put(t, store, "nova/")
+
+	for _, name := range []string{"vazia", "nova"} {
+		if err := store.Delete(ctx, name); !errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("Delete(%q) = %v, esperado ErrNotFound", name, err)
+		}
+		if !exists(store, name) {
+			t.Errorf("diretório %s removido por um Delete sem barra final", name)
+		}
+	}
+
+	if err := store.Delete(ctx, "vazia/"); err != nil {
+		t.Errorf("Delete(%q): %v", "vazia/", err)
+	}
+	if exists(store, "vazia") {
+		t.Error("diretório vazia/ não foi removido com a barra final")
+	}
+}
+
+func TestWalkFolderMarkers(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	put(t, store, "criada/")
+	put(t, store, "criada/arquivo.txt")
+	put(t, store, "implicita/arquivo.txt")
+	if err := os.MkdirAll(store.localPath("vazia"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	err := store.Walk(ctx, "", func(file storage.FileInfo) error {
+		names = append(names, file.Name)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk: %v", err)
+	}
+
+	want := []string{"criada/", "criada/arquivo.txt", "implicita/arquivo.txt", "vazia/"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("Walk = %v, esperado %v", names, want)
+	}
+}
@@ -0,0 +1,120 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"fileblobs/pkg/storage"
+	"fmt"
+
+	"github.com/minio/minio-go/v7"
+)
+
+var (
+	_ storage.ChunkedUploader = (*Store)(nil)
+	_ storage.ChunkSizer      = (*Store)(nil)
+)
+
+// minPartSize é o tamanho mínimo das partes de um multipart upload, exceto a última
+const minPartSize = 5 << 20
+
+// BeginChunks inicia um multipart upload. O S3 exige que todas as partes,
+// exceto a última, tenham pelo menos 5 MB.
+func (s *Store) BeginChunks(ctx context.Context, path string) (string, error) {
+	// O S3 define o tipo do objeto ao iniciar o upload, antes de receber o
+	// conteúdo; por isso ele vem da extensão e é corrigido em CommitChunks
+	uploadID, err := s.core().NewMultipartUpload(ctx, s.bucket, storage.CleanPath(path), minio.PutObjectOptions{
+		ContentType: storage.DetectContentType(path, nil),
+	})
+	if err != nil {
+		return "", fmt.Errorf("erro ao iniciar upload em partes: %w", err)
+	}
+	return uploadID, nil
+}
+
+func (s *Store) MinChunkSize() int {
+	return minPartSize
+}
+
+func (s *Store) StageChunk(ctx context.Context, path, uploadID string, index int, data []byte) error {
+	// As partes do S3 são numeradas a partir de 1
+	_, err := s.core().PutObjectPart(ctx, s.bucket, storage.CleanPath(path), uploadID, index+1,
+		bytes.NewReader(data), int64(len(data)), minio.PutObjectPartOptions{})
+	if err != nil {
+		return fmt.Errorf("erro ao enviar parte %d: %w", index, err)
+	}
+	return nil
+}
+
+// CommitChunks consulta os ETags das partes no servidor, já que eles não
+// são guardados entre as requisições, e conclui o multipart upload. Se o tipo
+// detectado no conteúdo difere do definido pela extensão em BeginChunks, o
+// objeto é copiado sobre si mesmo com o tipo correto.
+func (s *Store) CommitChunks(ctx context.Context, path, uploadID string, count int, contentType string, contentMD5 []byte) error {
+	path = storage.CleanPath(path)
+	core := s.core()
+
+	// Um multipart upload sem partes não pode ser concluído
+	if count == 0 {
+		if err := core.AbortMultipartUpload(ctx, s.bucket, path, uploadID); err != nil {
+			return fmt.Errorf("erro ao cancelar upload em partes: %w", err)
+		}
+		return s.Put(ctx, path, bytes.NewReader(nil))
+	}
+
+	parts := make([]minio.CompletePart, 0, count)
+	marker := 0
+	for {
+		result, err := core.ListObjectParts(ctx, s.bucket, path, uploadID, marker, 1000)
+		if err != nil {
+			return fmt.Errorf("erro ao listar partes do upload: %w", err)
+		}
+		for _, part := range result.ObjectParts {
+			if part.PartNumber <= count {
+				parts = append(parts, minio.CompletePart{PartNumber: part.PartNumber, ETag: part.ETag})
+			}
+		}
+		if !result.IsTruncated {
+			break
+		}
+		marker = result.NextPartNumberMarker
+	}
+
+	if len(parts) != count {
+		return fmt.Errorf("upload incompleto: %d de %d partes encontradas", len(parts), count)
+	}
+
+	if _, err := core.CompleteMultipartUpload(ctx, s.bucket, path, uploadID, parts, minio.PutObjectOptions{}); err != nil {
+		return fmt.Errorf("erro ao concluir upload em partes: %w", err)
+	}
+
+	if contentType == "" || contentType == storage.DetectContentType(path, nil) {
+		return nil
+	}
+	// UserMetadata leva o tipo também para a cópia em partes de objetos acima de 5 GB
+	_, err := s.client.ComposeObject(ctx,
+		minio.CopyDestOptions{
+			Bucket:          s.bucket,
+			Object:          path,
+			ContentType:     contentType,
+			ReplaceMetadata: true,
+			UserMetadata:    map[string]string{"Content-Type": contentType},
+		},
+		minio.CopySrcOptions{Bucket: s.bucket, Object: path},
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao gravar o tipo de %s: %w", path, err)
+	}
+	return nil
+}
+
+func (s *Store) AbortChunks(ctx context.Context, path, uploadID string) error {
+	if err := s.core().AbortMultipartUpload(ctx, s.bucket, storage.CleanPath(path), uploadID); err != nil {
+		return fmt.Errorf("erro ao cancelar upload em partes: %w", err)
+	}
+	return nil
+}
+
+// core expõe as operações de baixo nível do multipart upload
+func (s *Store) core() *minio.Core {
+	return &minio.Core{Client: s.client}
+}
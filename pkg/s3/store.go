@@ -0,0 +1,311 @@
+package s3
+
+import (
+	"context"
+	"fileblobs/pkg/storage"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// partSize limita a memória usada por upload quando o tamanho não é conhecido.
+// Com 10.000 partes, permite objetos de até ~160 GB.
+const partSize = 16 << 20
+
+// Store implementa storage.BlobStore sobre um bucket compatível com S3
+type Store struct {
+	client *minio.Client
+	bucket string
+}
+
+var (
+	_ storage.BlobStore              = (*Store)(nil)
+	_ storage.ConditionalRangeOpener = (*Store)(nil)
+)
+
+func init() {
+	storage.Register(storage.TypeS3, func(cfg storage.Config) (storage.BlobStore, error) {
+		return NewStore(cfg)
+	})
+}
+
+// NewStore cria um Store a partir do endpoint, credenciais e bucket da configuração.
+// O endpoint deve ser uma URL (ex.: http://localhost:9000); vazio usa o AWS S3.
+func NewStore(cfg storage.Config) (*Store, error) {
+	if cfg.Bucket == "" || cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, fmt.Errorf("credenciais ou bucket do S3 ausentes")
+	}
+
+	host, secure, err := parseEndpoint(cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	lookup := minio.BucketLookupAuto
+	if cfg.PathStyle {
+		lookup = minio.BucketLookupPath
+	}
+
+	client, err := minio.New(host, &minio.Options{
+		Creds:        credentials.NewStaticV4(cfg.AccessKeyID, cfg.SecretAccessKey, ""),
+		Secure:       secure,
+		Region:       cfg.Region,
+		BucketLookup: lookup,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro criando cliente S3: %w", err)
+	}
+
+	return &Store{client: client, bucket: cfg.Bucket}, nil
+}
+
+// parseEndpoint separa o host e o uso de TLS a partir da URL do endpoint
+func parseEndpoint(endpoint string) (host string, secure bool, err error) {
+	if endpoint == "" {
+		return "s3.amazonaws.com", true, nil
+	}
+	if !strings.Contains(endpoint, "://") {
+		return endpoint, true, nil
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", false, fmt.Errorf("endpoint S3 inválido: %w", err)
+	}
+	if u.Host == "" {
+		return "", false, fmt.Errorf("endpoint S3 inválido: %s", endpoint)
+	}
+	return u.Host, u.Scheme != "http", nil
+}
+
+func (s *Store) List(ctx context.Context, prefix string, opts storage.ListOptions) (storage.Listing, error) {
+	var listing storage.Listing
+	prefix = storage.CleanPath(prefix)
+
+	// Cancelar o contexto encerra a goroutine de listagem caso saiamos antes do fim
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	listOpts := minio.ListObjectsOptions{
+		Prefix:    prefix,
+		Recursive: opts.Recursive,
+	}
+	paged := !opts.Recursive && opts.MaxResults > 0
+	if paged {
+		listOpts.StartAfter = opts.Marker
+		listOpts.MaxKeys = opts.MaxResults + 1
+	}
+
+	objects := s.client.ListObjects(ctx, s.bucket, listOpts)
+
+	count := 0
+	last := ""
+	for obj := range objects {
+		if obj.Err != nil {
+			return storage.Listing{}, fmt.Errorf("erro ao listar objetos: %w", obj.Err)
+		}
+
+		isFolder := strings.HasSuffix(obj.Key, "/") && obj.Key != prefix
+		if !opts.Recursive && storage.IsFolderMarker(obj.Key) && !isFolder {
+			// Os marcadores de pasta não são arquivos
+			continue
+		}
+
+		if paged && count == opts.MaxResults {
+			listing.NextMarker = last
+			break
+		}
+		count++
+		last = obj.Key
+
+		if !opts.Recursive && isFolder {
+			// Prefixos comuns representam pastas. Para continuar depois de uma
+			// pasta, o marcador precisa vir depois de todas as chaves dentro dela.
+			listing.Folders = append(listing.Folders, strings.TrimSuffix(obj.Key, "/"))
+			last = obj.Key + string(utf8.MaxRune)
+			continue
+		}
+
+		listing.Files = append(listing.Files, fileInfo(obj))
+	}
+
+	return listing, nil
+}
+
+// Walk entrega os objetos abaixo do prefixo à medida que o S3 os lista
+func (s *Store) Walk(ctx context.Context, prefix string, fn storage.WalkFunc) error {
+	// Cancelar o contexto encerra a goroutine de listagem caso saiamos antes do fim
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	objects := s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{
+		Prefix:    storage.CleanPath(prefix),
+		Recursive: true,
+	})
+
+	for obj := range objects {
+		if obj.Err != nil {
+			return fmt.Errorf("erro ao listar objetos: %w", obj.Err)
+		}
+		if err := fn(fileInfo(obj)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) Stat(ctx context.Context, path string) (storage.FileInfo, error) {
+	path = storage.CleanPath(path)
+
+	obj, err := s.client.StatObject(ctx, s.bucket, path, minio.StatObjectOptions{})
+	if err != nil {
+		return storage.FileInfo{}, fmt.Errorf("erro ao obter propriedades do objeto: %w", wrapNotFound(err, path))
+	}
+	return fileInfo(obj), nil
+}
+
+func (s *Store) Open(ctx context.Context, path string) (io.ReadCloser, storage.FileInfo, error) {
+	path = storage.CleanPath(path)
+
+	obj, err := s.client.GetObject(ctx, s.bucket, path, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, storage.FileInfo{}, fmt.Errorf("erro ao baixar objeto: %w", wrapNotFound(err, path))
+	}
+
+	// GetObject só faz a requisição na primeira leitura; Stat força a verificação
+	stat, err := obj.Stat()
+	if err != nil {
+		obj.Close()
+		return nil, storage.FileInfo{}, fmt.Errorf("erro ao baixar objeto: %w", wrapNotFound(err, path))
+	}
+
+	return obj, fileInfo(stat), nil
+}
+
+func (s *Store) OpenRange(ctx context.Context, path string, offset, count int64) (io.ReadCloser, error) {
+	path = storage.CleanPath(path)
+
+	opts, err := rangeOptions(offset, count)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := s.client.GetObject(ctx, s.bucket, path, opts)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao baixar faixa do objeto: %w", wrapNotFound(err, path))
+	}
+	return obj, nil
+}
+
+// OpenRangeIfMatch lê a faixa apenas se o objeto ainda tiver o ETag informado
+func (s *Store) OpenRangeIfMatch(ctx context.Context, path string, offset, count int64, etag string) (io.ReadCloser, error) {
+	path = storage.CleanPath(path)
+
+	opts, err := rangeOptions(offset, count)
+	if err != nil {
+		return nil, err
+	}
+	if err := opts.SetMatchETag(strings.Trim(etag, `"`)); err != nil {
+		return nil, err
+	}
+
+	obj, err := s.client.GetObject(ctx, s.bucket, path, opts)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao baixar faixa do objeto: %w", wrapNotFound(err, path))
+	}
+
+	// Sem Stat, a condição só seria verificada na primeira leitura
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		return nil, fmt.Errorf("erro ao baixar faixa do objeto: %w", wrapNotFound(err, path))
+	}
+	return obj, nil
+}
+
+// rangeOptions pede count bytes a partir de offset; count 0 lê até o fim
+func rangeOptions(offset, count int64) (minio.GetObjectOptions, error) {
+	var opts minio.GetObjectOptions
+	if offset > 0 || count > 0 {
+		end := int64(0)
+		if count > 0 {
+			end = offset + count - 1
+		}
+		if err := opts.SetRange(offset, end); err != nil {
+			return opts, err
+		}
+	}
+	return opts, nil
+}
+
+func (s *Store) Put(ctx context.Context, path string, body io.Reader) error {
+	contentType, body := storage.SniffContentType(path, body)
+
+	_, err := s.client.PutObject(ctx, s.bucket, storage.CleanPath(path), body, -1, minio.PutObjectOptions{
+		PartSize:    partSize,
+		ContentType: contentType,
+	})
+	if err != nil {
+		return fmt.Errorf("erro ao fazer upload do objeto: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) Delete(ctx context.Context, path string) error {
+	path = storage.CleanPath(path)
+
+	// RemoveObject não falha para objetos inexistentes, então verificamos antes
+	if _, err := s.Stat(ctx, path); err != nil {
+		return err
+	}
+
+	if err := s.client.RemoveObject(ctx, s.bucket, path, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("erro ao excluir objeto: %w", err)
+	}
+	return nil
+}
+
+// Copy faz uma cópia no servidor; objetos acima de 5 GB são copiados em partes
+func (s *Store) Copy(ctx context.Context, src, dst string) error {
+	src = storage.CleanPath(src)
+
+	_, err := s.client.ComposeObject(ctx,
+		minio.CopyDestOptions{Bucket: s.bucket, Object: storage.CleanPath(dst)},
+		minio.CopySrcOptions{Bucket: s.bucket, Object: src},
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao copiar %s: %w", src, wrapNotFound(err, src))
+	}
+	return nil
+}
+
+func fileInfo(obj minio.ObjectInfo) storage.FileInfo {
+	info := storage.FileInfo{
+		Name:         obj.Key,
+		Size:         obj.Size,
+		LastModified: obj.LastModified,
+		ContentType:  obj.ContentType,
+		AccessTier:   obj.StorageClass,
+	}
+	if obj.ETag != "" {
+		info.ETag = `"` + strings.Trim(obj.ETag, `"`) + `"`
+	}
+	return info
+}
+
+// wrapNotFound converte os erros de objeto inexistente do S3 em storage.ErrNotFound
+// e os de condição If-Match em storage.ErrModified
+func wrapNotFound(err error, path string) error {
+	switch minio.ToErrorResponse(err).Code {
+	case "NoSuchKey", "NoSuchBucket", "NotFound":
+		return fmt.Errorf("%s: %w", path, storage.ErrNotFound)
+	case "PreconditionFailed":
+		return fmt.Errorf("%s: %w", path, storage.ErrModified)
+	}
+	return err
+}
@@ -0,0 +1,228 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fileblobs/pkg/storage"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Teste de integração contra um serviço compatível com S3 (MinIO, por exemplo).
+// Só roda com FILEBLOBS_S3_TEST_ENDPOINT definido, como em
+//
+//	FILEBLOBS_S3_TEST_ENDPOINT=http://localhost:9000 go test ./pkg/s3
+//
+// O bucket (FILEBLOBS_S3_TEST_BUCKET, padrão "fileblobs-test") deve existir. As
+// credenciais vêm de FILEBLOBS_S3_TEST_ACCESS_KEY e FILEBLOBS_S3_TEST_SECRET_KEY,
+// com as do MinIO como padrão. Os objetos são criados sob um prefixo próprio e
+// removidos ao final.
+func newTestStore(t *testing.T) (*Store, string) {
+	t.Helper()
+
+	endpoint := os.Getenv("FILEBLOBS_S3_TEST_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("FILEBLOBS_S3_TEST_ENDPOINT não definido")
+	}
+
+	store, err := NewStore(storage.Config{
+		Type:            storage.TypeS3,
+		Endpoint:        endpoint,
+		Region:          envOr("FILEBLOBS_S3_TEST_REGION", "us-east-1"),
+		AccessKeyID:     envOr("FILEBLOBS_S3_TEST_ACCESS_KEY", "minioadmin"),
+		SecretAccessKey: envOr("FILEBLOBS_S3_TEST_SECRET_KEY", "minioadmin"),
+		Bucket:          envOr("FILEBLOBS_S3_TEST_BUCKET", "fileblobs-test"),
+		PathStyle:       true,
+	})
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+
+	prefix := fmt.Sprintf("fileblobs-test-%d/", time.Now().UnixNano())
+	t.Cleanup(func() {
+		ctx := context.Background()
+		storage.Walk(ctx, store, prefix, func(file storage.FileInfo) error {
+			store.Delete(ctx, file.Name)
+			return nil
+		})
+	})
+	return store, prefix
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func put(t *testing.T, store *Store, path, content string) {
+	t.Helper()
+	if err := store.Put(context.Background(), path, strings.NewReader(content)); err != nil {
+		t.Fatalf("Put(%q): %v", path, err)
+	}
+}
+
+func read(t *testing.T, body io.ReadCloser) string {
+	t.Helper()
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("erro ao ler conteúdo: %v", err)
+	}
+	return string(data)
+}
+
+func TestStoreIntegration(t *testing.T) {
+	store, prefix := newTestStore(t)
+	ctx := context.Background()
+
+	files := map[string]string{
+		"a/one.txt":       "primeiro",
+		"a/sub/three.txt": "terceiro",
+		"a/two.txt":       "segundo arquivo",
+		"a/zz/four.txt":   "quarto",
+		"b.txt":           "fora da pasta",
+	}
+	for name, content := range files {
+		put(t, store, prefix+name, content)
+	}
+
+	t.Run("List com delimitador", func(t *testing.T) {
+		listing, err := store.List(ctx, prefix+"a/", storage.ListOptions{})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+
+		wantFolders := []string{prefix + "a/sub", prefix + "a/zz"}
+		if !slices.Equal(listing.Folders, wantFolders) {
+			t.Errorf("pastas = %v, esperado %v", listing.Folders, wantFolders)
+		}
+		wantFiles := []string{prefix + "a/one.txt", prefix + "a/two.txt"}
+		if got := fileNames(listing.Files); !slices.Equal(got, wantFiles) {
+			t.Errorf("arquivos = %v, esperado %v", got, wantFiles)
+		}
+		if listing.NextMarker != "" {
+			t.Errorf("NextMarker = %q sem paginação", listing.NextMarker)
+		}
+	})
+
+	t.Run("List paginado por marcador", func(t *testing.T) {
+		// Com uma entrada por página, o marcador precisa pular o conteúdo de
+		// a/sub/ para não repetir a pasta na página seguinte
+		var entries []string
+		marker := ""
+		for page := 0; ; page++ {
+			if page > 10 {
+				t.Fatalf("paginação não terminou; entradas: %v", entries)
+			}
+			listing, err := store.List(ctx, prefix+"a/", storage.ListOptions{MaxResults: 1, Marker: marker})
+			if err != nil {
+				t.Fatalf("List página %d: %v", page, err)
+			}
+			if n := len(listing.Folders) + len(listing.Files); n != 1 {
+				t.Fatalf("página %d com %d entradas, esperado 1", page, n)
+			}
+			entries = append(entries, listing.Folders...)
+			entries = append(entries, fileNames(listing.Files)...)
+			if listing.NextMarker == "" {
+				break
+			}
+			marker = listing.NextMarker
+		}
+
+		want := []string{prefix + "a/one.txt", prefix + "a/sub", prefix + "a/two.txt", prefix + "a/zz"}
+		if !slices.Equal(entries, want) {
+			t.Errorf("entradas = %v, esperado %v", entries, want)
+		}
+	})
+
+	t.Run("Open", func(t *testing.T) {
+		body, info, err := store.Open(ctx, prefix+"a/two.txt")
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		if got := read(t, body); got != files["a/two.txt"] {
+			t.Errorf("conteúdo = %q, esperado %q", got, files["a/two.txt"])
+		}
+		if info.Size != int64(len(files["a/two.txt"])) {
+			t.Errorf("Size = %d, esperado %d", info.Size, len(files["a/two.txt"]))
+		}
+		if info.ETag == "" {
+			t.Error("ETag vazio")
+		}
+	})
+
+	t.Run("OpenRange", func(t *testing.T) {
+		tests := []struct {
+			offset, count int64
+			want          string
+		}{
+			{0, 7, "segundo"},
+			{8, 0, "arquivo"},
+			{3, 4, "undo"},
+		}
+		for _, tt := range tests {
+			body, err := store.OpenRange(ctx, prefix+"a/two.txt", tt.offset, tt.count)
+			if err != nil {
+				t.Fatalf("OpenRange(%d, %d): %v", tt.offset, tt.count, err)
+			}
+			if got := read(t, body); got != tt.want {
+				t.Errorf("OpenRange(%d, %d) = %q, esperado %q", tt.offset, tt.count, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("Copy", func(t *testing.T) {
+		if err := store.Copy(ctx, prefix+"a/one.txt", prefix+"copia/one.txt"); err != nil {
+			t.Fatalf("Copy: %v", err)
+		}
+		body, _, err := store.Open(ctx, prefix+"copia/one.txt")
+		if err != nil {
+			t.Fatalf("Open da cópia: %v", err)
+		}
+		if got := read(t, body); got != files["a/one.txt"] {
+			t.Errorf("conteúdo da cópia = %q, esperado %q", got, files["a/one.txt"])
+		}
+
+		err = store.Copy(ctx, prefix+"nao-existe.txt", prefix+"copia/nao-existe.txt")
+		if !errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("Copy de origem inexistente = %v, esperado ErrNotFound", err)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		if err := store.Delete(ctx, prefix+"b.txt"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := store.Stat(ctx, prefix+"b.txt"); !errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("Stat após Delete = %v, esperado ErrNotFound", err)
+		}
+		if err := store.Delete(ctx, prefix+"b.txt"); !errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("Delete de arquivo inexistente = %v, esperado ErrNotFound", err)
+		}
+	})
+
+	t.Run("ErrNotFound", func(t *testing.T) {
+		missing := prefix + "nao-existe.txt"
+		if _, err := store.Stat(ctx, missing); !errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("Stat = %v, esperado ErrNotFound", err)
+		}
+		if _, _, err := store.Open(ctx, missing); !errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("Open = %v, esperado ErrNotFound", err)
+		}
+	})
+}
+
+func fileNames(files []storage.FileInfo) []string {
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		names = append(names, file.Name)
+	}
+	return names
+}
@@ -0,0 +1,93 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fileblobs/pkg/storage"
+	"fmt"
+	"io"
+
+	"github.com/minio/minio-go/v7"
+)
+
+var _ storage.Versioner = (*Store)(nil)
+
+// Versions lista as versões do objeto. Em buckets sem versionamento, o objeto
+// aparece como uma única versão "null"; marcadores de exclusão são omitidos.
+func (s *Store) Versions(ctx context.Context, path string) ([]storage.Version, error) {
+	path = storage.CleanPath(path)
+
+	// Cancelar o contexto encerra a goroutine de listagem caso saiamos antes do fim
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	objects := s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{
+		Prefix:       path,
+		Recursive:    true,
+		WithVersions: true,
+	})
+
+	var versions []storage.Version
+	for obj := range objects {
+		if obj.Err != nil {
+			return nil, fmt.Errorf("erro ao listar versões de %s: %w", path, obj.Err)
+		}
+		if obj.Key > path {
+			break
+		}
+		if obj.Key != path || obj.IsDeleteMarker {
+			continue
+		}
+
+		versions = append(versions, storage.Version{
+			ID:           obj.VersionID,
+			Current:      obj.IsLatest,
+			Size:         obj.Size,
+			LastModified: obj.LastModified,
+			Created:      obj.LastModified,
+			ContentType:  obj.ContentType,
+		})
+	}
+
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("%s: %w", path, storage.ErrNotFound)
+	}
+	storage.SortVersions(versions)
+	return versions, nil
+}
+
+func (s *Store) OpenVersion(ctx context.Context, path string, version storage.Version) (io.ReadCloser, storage.FileInfo, error) {
+	path = storage.CleanPath(path)
+
+	obj, err := s.client.GetObject(ctx, s.bucket, path, minio.GetObjectOptions{VersionID: version.ID})
+	if err != nil {
+		return nil, storage.FileInfo{}, fmt.Errorf("erro ao baixar versão do objeto: %w", wrapNotFound(err, path))
+	}
+
+	// GetObject só faz a requisição na primeira leitura; Stat força a verificação
+	stat, err := obj.Stat()
+	if err != nil {
+		obj.Close()
+		return nil, storage.FileInfo{}, fmt.Errorf("erro ao baixar versão do objeto: %w", wrapNotFound(err, path))
+	}
+
+	return obj, fileInfo(stat), nil
+}
+
+// RestoreVersion copia a versão sobre o objeto, o que cria uma nova versão
+// atual com o conteúdo dela
+func (s *Store) RestoreVersion(ctx context.Context, path string, version storage.Version) error {
+	if version.ID == "" {
+		return errors.New("versão não informada")
+	}
+	path = storage.CleanPath(path)
+
+	_, err := s.client.ComposeObject(ctx,
+		minio.CopyDestOptions{Bucket: s.bucket, Object: path},
+		minio.CopySrcOptions{Bucket: s.bucket, Object: path, VersionID: version.ID},
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao restaurar versão de %s: %w", path, wrapNotFound(err, path))
+	}
+	return nil
+}
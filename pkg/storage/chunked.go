@@ -0,0 +1,26 @@
+package storage
+
+import "context"
+
+// ChunkedUploader é implementado pelos backends que aceitam um upload enviado
+// em partes, em requisições separadas, e montado apenas ao final. As partes
+// são numeradas a partir de 0 e montadas na ordem dos índices.
+type ChunkedUploader interface {
+	// BeginChunks inicia um upload em partes e retorna o identificador
+	// que deve ser informado nas chamadas seguintes
+	BeginChunks(ctx context.Context, path string) (string, error)
+	// StageChunk grava a parte de índice index do upload
+	StageChunk(ctx context.Context, path, uploadID string, index int, data []byte) error
+	// CommitChunks monta o arquivo com as partes de 0 a count-1. contentType é o
+	// tipo MIME detectado pelo chamador e contentMD5 o hash do conteúdo montado
+	// (nil quando desconhecido), gravados pelos backends que os armazenam.
+	CommitChunks(ctx context.Context, path, uploadID string, count int, contentType string, contentMD5 []byte) error
+	// AbortChunks descarta as partes já enviadas
+	AbortChunks(ctx context.Context, path, uploadID string) error
+}
+
+// ChunkSizer é implementado pelos backends que exigem um tamanho mínimo para
+// todas as partes de um upload, exceto a última, como o S3
+type ChunkSizer interface {
+	MinChunkSize() int
+}
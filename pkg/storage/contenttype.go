@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"bufio"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+)
+
+// sniffLen é a quantidade de bytes que http.DetectContentType examina
+const sniffLen = 512
+
+// DetectContentType define o tipo MIME de um arquivo pela extensão do nome e,
+// quando a extensão não é conhecida, pelo início do conteúdo (head)
+func DetectContentType(name string, head []byte) string {
+	if contentType := mime.TypeByExtension(path.Ext(name)); contentType != "" {
+		return contentType
+	}
+	if len(head) == 0 {
+		return "application/octet-stream"
+	}
+	return http.DetectContentType(head)
+}
+
+// SniffContentType detecta o tipo MIME de um conteúdo que ainda será lido e
+// retorna um reader equivalente ao original, sem perder os bytes examinados
+func SniffContentType(name string, body io.Reader) (string, io.Reader) {
+	buffered := bufio.NewReaderSize(body, sniffLen)
+	// Um erro aqui (como um conteúdo menor que sniffLen) aparece de novo na leitura
+	head, _ := buffered.Peek(sniffLen)
+	return DetectContentType(name, head), buffered
+}
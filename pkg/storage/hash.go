@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ContentMD5 retorna o MD5 do conteúdo de um arquivo: a propriedade Content-MD5,
+// quando o backend a guarda, o ETag, quando ele é o hash do conteúdo, ou, sem
+// nenhum dos dois, o hash calculado lendo o arquivo inteiro
+func ContentMD5(ctx context.Context, store BlobStore, info FileInfo) ([]byte, error) {
+	if len(info.ContentMD5) > 0 {
+		return info.ContentMD5, nil
+	}
+	if md5 := ETagMD5(info.ETag); md5 != nil {
+		return md5, nil
+	}
+
+	body, _, err := store.Open(ctx, info.Name)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	hash := md5.New()
+	if _, err := io.Copy(hash, body); err != nil {
+		return nil, fmt.Errorf("erro ao ler %s: %w", info.Name, err)
+	}
+	return hash.Sum(nil), nil
+}
+
+// ETagMD5 retorna o MD5 contido no ETag, quando ele é o hash do conteúdo, como
+// nos objetos do S3 enviados em uma única requisição. ETags de uploads em
+// partes ("<hash>-<partes>") e de outros backends retornam nil.
+func ETagMD5(etag string) []byte {
+	etag = strings.Trim(etag, `"`)
+	if len(etag) != 32 {
+		return nil
+	}
+	md5, err := hex.DecodeString(etag)
+	if err != nil {
+		return nil
+	}
+	return md5
+}
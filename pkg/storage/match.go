@@ -0,0 +1,25 @@
+package storage
+
+import (
+	"regexp"
+	"strings"
+)
+
+// GlobRegexp converte um caminho com curingas, como "2024/*/notas/*.pdf", em
+// uma expressão regular. Cada "*" corresponde a um trecho de nome sem barras,
+// e o padrão casa com o próprio caminho e com tudo o que estiver abaixo dele.
+// A comparação ignora maiúsculas e minúsculas.
+func GlobRegexp(glob string) (*regexp.Regexp, error) {
+	pattern := regexp.QuoteMeta(glob)
+	pattern = strings.ReplaceAll(pattern, `\*`, "[^/]+")
+	return regexp.Compile("(?i)^" + pattern + "(/|$)")
+}
+
+// GlobPrefix retorna as pastas fixas do início do padrão, antes do primeiro
+// curinga, permitindo listar apenas a parte do armazenamento que pode casar
+func GlobPrefix(glob string) string {
+	if i := strings.Index(glob, "*"); i >= 0 {
+		glob = glob[:i]
+	}
+	return glob[:strings.LastIndex(glob, "/")+1]
+}
@@ -0,0 +1,60 @@
+package storage
+
+import "testing"
+
+func TestGlobRegexp(t *testing.T) {
+	tests := []struct {
+		name string
+		glob string
+		path string
+		want bool
+	}{
+		{"caminho exato", "docs/a.txt", "docs/a.txt", true},
+		{"conteúdo abaixo do caminho", "docs", "docs/a.txt", true},
+		{"nome que só começa igual", "docs", "docs2/a.txt", false},
+		{"maiúsculas e minúsculas", "Docs/*.PDF", "docs/nota.pdf", true},
+		{"curinga dentro de um nome", "2024/*/notas/*.pdf", "2024/jan/notas/a.pdf", true},
+		{"curinga não atravessa barras", "2024/*/notas", "2024/jan/fev/notas", false},
+		{"curinga no fim não atravessa barras no nome", "docs/*.txt", "docs/sub/a.txt", false},
+		{"curinga no fim casa com o que está abaixo", "docs/*", "docs/sub/a.txt", true},
+		{"curinga não casa com nome vazio", "docs/*/a.txt", "docs//a.txt", false},
+		{"ponto é literal", "a.txt", "abtxt", false},
+		{"parênteses e mais são literais", "f(1)+x.txt", "f(1)+x.txt", true},
+		{"colchetes são literais", "[a].txt", "a.txt", false},
+		{"colchetes casam com eles mesmos", "[a].txt", "[a].txt", true},
+		{"interrogação é literal", "a?.txt", "ab.txt", false},
+		{"circunflexo e cifrão são literais", "^a$/b", "^a$/b", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := GlobRegexp(tt.glob)
+			if err != nil {
+				t.Fatalf("GlobRegexp(%q): %v", tt.glob, err)
+			}
+			if got := re.MatchString(tt.path); got != tt.want {
+				t.Errorf("GlobRegexp(%q) casa com %q = %v, esperado %v", tt.glob, tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlobPrefix(t *testing.T) {
+	tests := []struct {
+		glob string
+		want string
+	}{
+		{"2024/*/notas/*.pdf", "2024/"},
+		{"2024/jan/nota*.pdf", "2024/jan/"},
+		{"2024/jan/*", "2024/jan/"},
+		{"*.pdf", ""},
+		{"a*/b/c", ""},
+		{"docs/a.txt", "docs/"},
+		{"docs", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := GlobPrefix(tt.glob); got != tt.want {
+			t.Errorf("GlobPrefix(%q) = %q, esperado %q", tt.glob, got, tt.want)
+		}
+	}
+}
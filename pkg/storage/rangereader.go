@@ -0,0 +1,182 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ErrModified é retornado quando o arquivo foi alterado depois de consultado,
+// e uma leitura condicionada ao seu ETag não pode continuar
+var ErrModified = errors.New("o arquivo foi alterado durante a leitura")
+
+// ConditionalRangeOpener é implementado pelos backends que leem uma faixa
+// apenas se o arquivo ainda tiver o ETag informado (If-Match), retornando
+// ErrModified caso contrário
+type ConditionalRangeOpener interface {
+	OpenRangeIfMatch(ctx context.Context, path string, offset, count int64, etag string) (io.ReadCloser, error)
+}
+
+// RangeReader implementa io.ReadSeeker sobre um arquivo de um BlobStore.
+// Cada posicionamento abre uma nova leitura por faixa (OpenRange) apenas
+// quando os dados são realmente lidos, de modo que http.ServeContent consegue
+// atender requisições Range sem baixar o arquivo inteiro. Nos backends que
+// aceitam, cada leitura exige o ETag de info, para que uma resposta nunca
+// misture o conteúdo de duas versões do arquivo.
+type RangeReader struct {
+	ctx    context.Context
+	store  BlobStore
+	info   FileInfo
+	ranges []byteRange
+	offset int64
+	body   io.ReadCloser
+}
+
+// byteRange é uma faixa [start, end) pedida no cabeçalho Range
+type byteRange struct {
+	start, end int64
+}
+
+// NewRangeReader cria um RangeReader para o arquivo descrito por info.
+// rangeHeader é o cabeçalho Range da requisição, que limita cada leitura ao
+// fim da faixa pedida; vazio, as leituras vão até o fim do arquivo.
+func NewRangeReader(ctx context.Context, store BlobStore, info FileInfo, rangeHeader string) *RangeReader {
+	return &RangeReader{ctx: ctx, store: store, info: info, ranges: parseRange(rangeHeader, info.Size)}
+}
+
+func (r *RangeReader) Read(p []byte) (int, error) {
+	for {
+		if r.offset >= r.info.Size {
+			return 0, io.EOF
+		}
+
+		opened := false
+		if r.body == nil {
+			body, err := r.open(r.offset, r.count(r.offset))
+			if err != nil {
+				return 0, err
+			}
+			r.body = body
+			opened = true
+		}
+
+		n, err := r.body.Read(p)
+		r.offset += int64(n)
+
+		// Uma leitura limitada a uma faixa termina antes do fim do arquivo; se
+		// o ServeContent continuar lendo, como quando ignora o Range, uma nova
+		// leitura segue a partir daí
+		if errors.Is(err, io.EOF) && r.offset < r.info.Size {
+			r.body.Close()
+			r.body = nil
+			if n > 0 {
+				return n, nil
+			}
+			if opened {
+				return 0, io.ErrUnexpectedEOF
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+// count retorna quantos bytes ler a partir de offset: até o fim da faixa
+// pedida que o contém, ou 0 (até o fim do arquivo) fora delas
+func (r *RangeReader) count(offset int64) int64 {
+	for _, ra := range r.ranges {
+		if offset >= ra.start && offset < ra.end {
+			return ra.end - offset
+		}
+	}
+	return 0
+}
+
+func (r *RangeReader) open(offset, count int64) (io.ReadCloser, error) {
+	if opener, ok := r.store.(ConditionalRangeOpener); ok && r.info.ETag != "" {
+		return opener.OpenRangeIfMatch(r.ctx, r.info.Name, offset, count, r.info.ETag)
+	}
+	return r.store.OpenRange(r.ctx, r.info.Name, offset, count)
+}
+
+func (r *RangeReader) Seek(offset int64, whence int) (int64, error) {
+	var abs int64
+	switch whence {
+	case io.SeekStart:
+		abs = offset
+	case io.SeekCurrent:
+		abs = r.offset + offset
+	case io.SeekEnd:
+		abs = r.info.Size + offset
+	default:
+		return 0, fmt.Errorf("posicionamento inválido: %d", whence)
+	}
+	if abs < 0 {
+		return 0, errors.New("posição negativa")
+	}
+
+	// Mudar de posição descarta a leitura em andamento
+	if abs != r.offset && r.body != nil {
+		r.body.Close()
+		r.body = nil
+	}
+	r.offset = abs
+	return abs, nil
+}
+
+// Close encerra a leitura em andamento, se houver
+func (r *RangeReader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	err := r.body.Close()
+	r.body = nil
+	return err
+}
+
+// parseRange interpreta um cabeçalho "bytes=0-99,200-,-50" para um arquivo de
+// size bytes. Faixas inválidas ou fora do arquivo são ignoradas: as faixas
+// servem apenas para limitar as leituras, e quem decide o que é enviado é o
+// http.ServeContent.
+func parseRange(header string, size int64) []byteRange {
+	spec, ok := strings.CutPrefix(header, "bytes=")
+	if !ok {
+		return nil
+	}
+
+	var ranges []byteRange
+	for _, part := range strings.Split(spec, ",") {
+		first, last, ok := strings.Cut(strings.TrimSpace(part), "-")
+		if !ok {
+			continue
+		}
+
+		var ra byteRange
+		if first == "" {
+			// "-n" são os últimos n bytes
+			n, err := strconv.ParseInt(last, 10, 64)
+			if err != nil || n <= 0 {
+				continue
+			}
+			ra = byteRange{start: max(size-n, 0), end: size}
+		} else {
+			start, err := strconv.ParseInt(first, 10, 64)
+			if err != nil || start < 0 || start >= size {
+				continue
+			}
+			ra = byteRange{start: start, end: size}
+			if last != "" {
+				end, err := strconv.ParseInt(last, 10, 64)
+				if err != nil || end < start {
+					continue
+				}
+				ra.end = min(end+1, size)
+			}
+		}
+		ranges = append(ranges, ra)
+	}
+	return ranges
+}
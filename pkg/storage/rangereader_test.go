@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rangeStore guarda um único arquivo e registra as faixas abertas
+type rangeStore struct {
+	BlobStore
+	data   []byte
+	etag   string
+	opened []byteRange
+}
+
+func (s *rangeStore) OpenRange(ctx context.Context, path string, offset, count int64) (io.ReadCloser, error) {
+	end := int64(len(s.data))
+	if count > 0 {
+		end = min(offset+count, end)
+	}
+	s.opened = append(s.opened, byteRange{start: offset, end: end})
+	return io.NopCloser(bytes.NewReader(s.data[offset:end])), nil
+}
+
+func (s *rangeStore) OpenRangeIfMatch(ctx context.Context, path string, offset, count int64, etag string) (io.ReadCloser, error) {
+	if etag != s.etag {
+		return nil, fmt.Errorf("%s: %w", path, ErrModified)
+	}
+	return s.OpenRange(ctx, path, offset, count)
+}
+
+func newRangeStore() (*rangeStore, FileInfo) {
+	data := []byte("0123456789abcdefghijklmnopqrstuvwxyz")
+	store := &rangeStore{data: data, etag: `"v1"`}
+	return store, FileInfo{Name: "a.txt", Size: int64(len(data)), ETag: `"v1"`, ContentType: "text/plain"}
+}
+
+// serveRange atende uma requisição com o cabeçalho Range informado
+func serveRange(store *rangeStore, info FileInfo, rangeHeader string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/a.txt", nil)
+	if rangeHeader != "" {
+		req.Header.Set("Range", rangeHeader)
+	}
+	rec := httptest.NewRecorder()
+	reader := NewRangeReader(req.Context(), store, info, rangeHeader)
+	defer reader.Close()
+	http.ServeContent(rec, req, info.Name, time.Time{}, reader)
+	return rec
+}
+
+func TestRangeReaderSingleRange(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		status int
+		body   string
+		opened []byteRange
+	}{
+		{"sem Range", "", http.StatusOK, "0123456789abcdefghijklmnopqrstuvwxyz", []byteRange{{0, 36}}},
+		{"faixa fechada", "bytes=10-19", http.StatusPartialContent, "abcdefghij", []byteRange{{10, 20}}},
+		{"faixa aberta", "bytes=30-", http.StatusPartialContent, "uvwxyz", []byteRange{{30, 36}}},
+		{"sufixo", "bytes=-5", http.StatusPartialContent, "vwxyz", []byteRange{{31, 36}}},
+		{"fim além do arquivo", "bytes=34-99", http.StatusPartialContent, "yz", []byteRange{{34, 36}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, info := newRangeStore()
+			rec := serveRange(store, info, tt.header)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, esperado %d", rec.Code, tt.status)
+			}
+			if got := rec.Body.String(); got != tt.body {
+				t.Errorf("corpo = %q, esperado %q", got, tt.body)
+			}
+			if fmt.Sprint(store.opened) != fmt.Sprint(tt.opened) {
+				t.Errorf("faixas abertas = %v, esperado %v", store.opened, tt.opened)
+			}
+		})
+	}
+}
+
+func TestRangeReaderMultiRange(t *testing.T) {
+	store, info := newRangeStore()
+	rec := serveRange(store, info, "bytes=0-4,10-14")
+
+	if rec.Code != http.StatusPartialContent {
+		t.Fatalf("status = %d, esperado %d", rec.Code, http.StatusPartialContent)
+	}
+	mediaType, params, err := mime.ParseMediaType(rec.Header().Get("Content-Type"))
+	if err != nil || mediaType != "multipart/byteranges" {
+		t.Fatalf("Content-Type = %q, esperado multipart/byteranges", rec.Header().Get("Content-Type"))
+	}
+
+	var parts []string
+	mr := multipart.NewReader(rec.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("erro ao ler parte: %v", err)
+		}
+		body, _ := io.ReadAll(part)
+		parts = append(parts, part.Header.Get("Content-Range")+" "+string(body))
+	}
+
+	want := []string{"bytes 0-4/36 01234", "bytes 10-14/36 abcde"}
+	if strings.Join(parts, "|") != strings.Join(want, "|") {
+		t.Errorf("partes = %q, esperado %q", parts, want)
+	}
+	if got, want := fmt.Sprint(store.opened), fmt.Sprint([]byteRange{{0, 5}, {10, 15}}); got != want {
+		t.Errorf("faixas abertas = %s, esperado %s", got, want)
+	}
+}
+
+func TestRangeReaderModified(t *testing.T) {
+	store, info := newRangeStore()
+	store.etag = `"v2"`
+
+	reader := NewRangeReader(context.Background(), store, info, "bytes=0-4")
+	defer reader.Close()
+	if _, err := io.ReadAll(reader); !errors.Is(err, ErrModified) {
+		t.Errorf("erro = %v, esperado ErrModified", err)
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []byteRange
+	}{
+		{"", nil},
+		{"items=0-4", nil},
+		{"bytes=0-4", []byteRange{{0, 5}}},
+		{"bytes=-5", []byteRange{{5, 10}}},
+		{"bytes=-50", []byteRange{{0, 10}}},
+		{"bytes=8-", []byteRange{{8, 10}}},
+		{"bytes=0-1, 4-5", []byteRange{{0, 2}, {4, 6}}},
+		{"bytes=5-2,x-1,20-,3-4", []byteRange{{3, 5}}},
+	}
+	for _, tt := range tests {
+		if got := parseRange(tt.header, 10); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("parseRange(%q) = %v, esperado %v", tt.header, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// DeletedFile é um arquivo excluído que ainda pode ser recuperado
+type DeletedFile struct {
+	FileInfo
+	// DeletedTime é quando o arquivo foi excluído; zero quando o backend não informa
+	DeletedTime time.Time
+	// RemainingRetentionDays é quantos dias faltam para a exclusão definitiva; -1 quando desconhecido
+	RemainingRetentionDays int
+	// VersionsOnly indica que restam apenas versões anteriores do arquivo, como
+	// acontece no Azure com o versionamento ativo
+	VersionsOnly bool
+}
+
+// DeletedWalkFunc recebe cada arquivo excluído de uma listagem da lixeira
+type DeletedWalkFunc func(DeletedFile) error
+
+// RecycleBin é implementado pelos backends que mantêm os arquivos excluídos
+// por um período antes de apagá-los, como o Azure com a exclusão reversível
+type RecycleBin interface {
+	// WalkDeleted chama fn para cada arquivo excluído abaixo do prefixo, em
+	// ordem de nome; um erro de fn encerra a listagem e SkipAll a encerra sem erro
+	WalkDeleted(ctx context.Context, prefix string, fn DeletedWalkFunc) error
+	// Undelete recupera o arquivo excluído
+	Undelete(ctx context.Context, path string) error
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Tipos de armazenamento suportados por padrão
+const (
+	TypeAzure      = "azure"
+	TypeFilesystem = "filesystem"
+	TypeS3         = "s3"
+)
+
+// Config reúne os parâmetros de conexão de uma conta de armazenamento.
+// Cada backend usa apenas os campos que lhe dizem respeito.
+type Config struct {
+	Type string
+
+	// Azure Blob Storage
+	AccountName   string
+	AccountKey    string
+	ContainerName string
+
+	// Sistema de arquivos local
+	RootPath string
+
+	// Serviços compatíveis com S3 (AWS, MinIO...)
+	Endpoint        string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	Bucket          string
+	PathStyle       bool
+}
+
+// Driver cria um BlobStore a partir de uma configuração
+type Driver func(cfg Config) (BlobStore, error)
+
+// Evictor descarta os recursos em cache (como clientes) de uma configuração
+type Evictor func(cfg Config)
+
+var (
+	driversMu sync.RWMutex
+	drivers   = make(map[string]Driver)
+	evictors  = make(map[string]Evictor)
+)
+
+// Register disponibiliza um backend com o nome informado.
+// Os backends se registram na inicialização dos seus pacotes.
+func Register(name string, driver Driver) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+
+	if driver == nil {
+		panic("storage: driver nulo para " + name)
+	}
+	if _, dup := drivers[name]; dup {
+		panic("storage: driver registrado duas vezes: " + name)
+	}
+	drivers[name] = driver
+}
+
+// RegisterEvictor registra a função que descarta os recursos em cache de um backend
+func RegisterEvictor(name string, evict Evictor) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	evictors[name] = evict
+}
+
+// Evict descarta os recursos em cache da configuração, se o backend mantiver algum.
+// Deve ser chamado quando as credenciais ou o destino de uma conta mudam.
+func Evict(cfg Config) {
+	if cfg.Type == "" {
+		cfg.Type = TypeAzure
+	}
+
+	driversMu.RLock()
+	evict, ok := evictors[cfg.Type]
+	driversMu.RUnlock()
+
+	if ok {
+		evict(cfg)
+	}
+}
+
+// Open cria o BlobStore correspondente ao tipo da configuração.
+// Configurações sem tipo são tratadas como Azure, por compatibilidade.
+func Open(cfg Config) (BlobStore, error) {
+	if cfg.Type == "" {
+		cfg.Type = TypeAzure
+	}
+
+	driversMu.RLock()
+	driver, ok := drivers[cfg.Type]
+	driversMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("tipo de armazenamento desconhecido: %q", cfg.Type)
+	}
+	return driver(cfg)
+}
@@ -0,0 +1,20 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// URLSigner é implementado pelos backends que geram URLs temporárias de
+// leitura, permitindo que outro serviço busque o arquivo diretamente
+type URLSigner interface {
+	// SignedURL retorna uma URL de leitura do arquivo válida pelo tempo informado
+	SignedURL(ctx context.Context, path string, expiry time.Duration) (string, error)
+}
+
+// RemoteCopier é implementado pelos backends que copiam o conteúdo de uma URL
+// no próprio servidor de armazenamento, sem que os bytes passem pelo fileblobs
+type RemoteCopier interface {
+	// CopyFromURL copia a URL para o caminho informado e aguarda a conclusão
+	CopyFromURL(ctx context.Context, srcURL, dst string) error
+}
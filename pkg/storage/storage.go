@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrNotFound é retornado quando o arquivo solicitado não existe no armazenamento
+var ErrNotFound = errors.New("arquivo não encontrado")
+
+// KeepFileName é o nome do marcador de pasta na convenção ".keep"
+const KeepFileName = ".keep"
+
+// FileInfo descreve um arquivo armazenado em um BlobStore
+type FileInfo struct {
+	Name         string // Caminho completo do arquivo dentro do container
+	Size         int64
+	LastModified time.Time
+	ContentType  string
+	ETag         string
+	AccessTier   string // Camada de acesso (Hot, Cool, Archive) ou classe de armazenamento no S3
+	ContentMD5   []byte // Hash MD5 do conteúdo, quando o backend o armazena
+	// ArchiveStatus indica a reidratação em andamento de um arquivo na camada
+	// Archive, como "rehydrate-pending-to-hot", com a prioridade em RehydratePriority
+	ArchiveStatus     string
+	RehydratePriority string
+}
+
+// ListOptions controla o comportamento de BlobStore.List
+type ListOptions struct {
+	// Recursive lista todos os arquivos abaixo do prefixo, sem agrupar pastas.
+	// Ao contrário da listagem por nível, inclui os marcadores de pastas vazias.
+	Recursive bool
+
+	// MaxResults limita a quantidade de pastas e arquivos de uma listagem por
+	// nível; zero lista tudo. Os itens vêm em ordem de nome e, se houver mais,
+	// Listing.NextMarker indica onde continuar.
+	MaxResults int
+	// Marker continua uma listagem a partir do NextMarker da página anterior
+	Marker string
+}
+
+// Listing é o resultado de uma listagem
+type Listing struct {
+	Folders    []string // Caminhos completos das pastas, sem a barra final
+	Files      []FileInfo
+	NextMarker string // Vazio quando não há mais itens
+}
+
+// BlobStore é a interface comum a todos os backends de armazenamento.
+// Os caminhos usam sempre "/" como separador e não começam com barra.
+type BlobStore interface {
+	// List lista pastas e arquivos diretamente abaixo do prefixo, ou todos os
+	// arquivos abaixo dele quando opts.Recursive é verdadeiro
+	List(ctx context.Context, prefix string, opts ListOptions) (Listing, error)
+	// Stat retorna as propriedades de um arquivo
+	Stat(ctx context.Context, path string) (FileInfo, error)
+	// Open abre o conteúdo de um arquivo para leitura; o chamador deve fechar o reader
+	Open(ctx context.Context, path string) (io.ReadCloser, FileInfo, error)
+	// OpenRange abre count bytes a partir de offset; count 0 lê até o fim do arquivo
+	OpenRange(ctx context.Context, path string, offset, count int64) (io.ReadCloser, error)
+	// Put grava o conteúdo de body no caminho informado, substituindo o arquivo existente
+	Put(ctx context.Context, path string, body io.Reader) error
+	// Delete remove um arquivo
+	Delete(ctx context.Context, path string) error
+	// Copy copia um arquivo dentro do mesmo armazenamento
+	Copy(ctx context.Context, src, dst string) error
+}
+
+// CleanPath normaliza um caminho de blob removendo barras iniciais
+// e substituindo barras invertidas (importante para Windows)
+func CleanPath(path string) string {
+	path = filepath.ToSlash(path)
+	path = strings.ReplaceAll(path, "\\", "/")
+	return strings.TrimLeft(path, "/")
+}
+
+// IsFolderMarker indica se o caminho é apenas o marcador de uma pasta vazia:
+// um arquivo vazio terminado em "/" ou um arquivo ".keep". Os marcadores não
+// aparecem como arquivos na listagem por nível, mas entram na recursiva para
+// que excluir, mover ou copiar uma pasta leve junto as subpastas vazias.
+func IsFolderMarker(name string) bool {
+	return strings.HasSuffix(name, "/") || path.Base(name) == KeepFileName
+}
@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limites das tags de índice do Azure
+const (
+	MaxTags           = 10
+	MaxTagKeyLength   = 128
+	MaxTagValueLength = 256
+)
+
+var (
+	// ErrTooManyTags é retornado quando um arquivo ficaria com mais de MaxTags tags
+	ErrTooManyTags = fmt.Errorf("um arquivo pode ter no máximo %d tags", MaxTags)
+	// ErrInvalidTagQuery é retornado quando a expressão de busca por tags é inválida
+	ErrInvalidTagQuery = errors.New("expressão de busca por tags inválida")
+)
+
+// TaggedFile é um arquivo encontrado por FindByTags, com as tags usadas na expressão
+type TaggedFile struct {
+	Name string
+	Tags map[string]string
+}
+
+// TaggedWalkFunc recebe cada arquivo encontrado por FindByTags
+type TaggedWalkFunc func(TaggedFile) error
+
+// Tagger é implementado pelos backends com tags de índice, pares chave/valor
+// que classificam os arquivos e permitem buscá-los, como no Azure
+type Tagger interface {
+	// Tags retorna as tags do arquivo
+	Tags(ctx context.Context, path string) (map[string]string, error)
+	// SetTags substitui todas as tags do arquivo; um mapa vazio remove todas
+	SetTags(ctx context.Context, path string, tags map[string]string) error
+	// FindByTags chama fn para cada arquivo que atende à expressão, como
+	// "cliente" = 'Acme' AND "ano" >= '2023'; um erro de fn encerra a busca e
+	// SkipAll a encerra sem erro
+	FindByTags(ctx context.Context, where string, fn TaggedWalkFunc) error
+}
+
+// ValidateTags verifica as tags contra os limites do Azure: até MaxTags tags,
+// chaves de 1 a 128 e valores de até 256 caracteres, usando apenas letras,
+// números, espaço e + - . / : = _
+func ValidateTags(tags map[string]string) error {
+	if len(tags) > MaxTags {
+		return ErrTooManyTags
+	}
+	for key, value := range tags {
+		if key == "" || utf8.RuneCountInString(key) > MaxTagKeyLength {
+			return fmt.Errorf("a chave %q deve ter de 1 a %d caracteres", key, MaxTagKeyLength)
+		}
+		if utf8.RuneCountInString(value) > MaxTagValueLength {
+			return fmt.Errorf("o valor da chave %q deve ter até %d caracteres", key, MaxTagValueLength)
+		}
+		if !validTagText(key) || !validTagText(value) {
+			return fmt.Errorf("a tag %q contém caracteres não permitidos; use letras, números, espaço e + - . / : = _", key)
+		}
+	}
+	return nil
+}
+
+func validTagText(s string) bool {
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case strings.ContainsRune(" +-./:=_", r):
+		default:
+			return false
+		}
+	}
+	return true
+}
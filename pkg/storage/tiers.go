@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"context"
+	"errors"
+)
+
+// TierArchive é a camada offline do Azure: o conteúdo só pode ser lido depois
+// que o arquivo é reidratado para uma camada online
+const TierArchive = "Archive"
+
+// Tiers são as camadas de acesso que podem ser atribuídas aos arquivos
+var Tiers = []string{"Hot", "Cool", "Cold", TierArchive}
+
+// Prioridades da reidratação de arquivos na camada Archive
+const (
+	RehydrateStandard = "Standard"
+	RehydrateHigh     = "High"
+)
+
+var (
+	// ErrArchived é retornado ao ler um arquivo que está na camada Archive
+	ErrArchived = errors.New("o arquivo está arquivado e precisa ser reidratado")
+	// ErrRehydrating é retornado ao alterar a camada de um arquivo que já está sendo reidratado
+	ErrRehydrating = errors.New("o arquivo já está sendo reidratado")
+)
+
+// Tierer é implementado pelos backends cujos arquivos têm camadas de acesso,
+// como o Azure (Hot, Cool, Cold e Archive)
+type Tierer interface {
+	// SetTier move o arquivo para a camada. Sair da camada Archive inicia a
+	// reidratação com a prioridade informada (RehydrateStandard ou
+	// RehydrateHigh; vazio usa a padrão), acompanhada em FileInfo.ArchiveStatus.
+	SetTier(ctx context.Context, path, tier, priority string) error
+}
+
+// IsArchived indica se o conteúdo do arquivo está indisponível por estar na
+// camada Archive, mesmo que a reidratação já tenha sido solicitada
+func IsArchived(info FileInfo) bool {
+	return info.AccessTier == TierArchive
+}
@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"sort"
+	"time"
+)
+
+// Version é uma versão anterior ou um snapshot de um arquivo
+type Version struct {
+	// ID identifica a versão; nos snapshots do Azure é a data do snapshot
+	ID           string
+	Snapshot     bool
+	Current      bool // é o conteúdo atual do arquivo
+	Size         int64
+	LastModified time.Time
+	// Created é quando a versão ou o snapshot foi criado
+	Created     time.Time
+	ContentType string
+}
+
+// Versioner é implementado pelos backends que guardam o histórico dos
+// arquivos, como o Azure com versionamento de blobs ou snapshots e os
+// buckets S3 com versionamento
+type Versioner interface {
+	// Versions lista as versões e os snapshots do arquivo, da mais recente para a mais antiga
+	Versions(ctx context.Context, path string) ([]Version, error)
+	// OpenVersion abre o conteúdo de uma versão; apenas ID e Snapshot são usados
+	OpenVersion(ctx context.Context, path string, version Version) (io.ReadCloser, FileInfo, error)
+	// RestoreVersion copia a versão sobre o arquivo, tornando-a o conteúdo atual
+	RestoreVersion(ctx context.Context, path string, version Version) error
+}
+
+// SortVersions ordena as versões com a atual primeiro e as demais da mais
+// recente para a mais antiga
+func SortVersions(versions []Version) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		if versions[i].Current != versions[j].Current {
+			return versions[i].Current
+		}
+		return versions[i].Created.After(versions[j].Created)
+	})
+}
@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"context"
+	"errors"
+)
+
+// SkipAll, retornado por uma WalkFunc, encerra a listagem sem erro, como fs.SkipAll
+var SkipAll = errors.New("listagem interrompida")
+
+// WalkFunc recebe cada arquivo de uma listagem recursiva
+type WalkFunc func(FileInfo) error
+
+// Walker é implementado pelos backends que entregam a listagem recursiva aos
+// poucos, página por página, em vez de montá-la inteira na memória
+type Walker interface {
+	// Walk chama fn para cada arquivo abaixo do prefixo, na mesma ordem e com os
+	// mesmos itens de List com opts.Recursive; um erro de fn encerra a listagem
+	Walk(ctx context.Context, prefix string, fn WalkFunc) error
+}
+
+// Walk percorre recursivamente os arquivos abaixo do prefixo. Usa o Walker do
+// backend quando disponível e, nos demais, a listagem recursiva completa.
+func Walk(ctx context.Context, store BlobStore, prefix string, fn WalkFunc) error {
+	err := walk(ctx, store, prefix, fn)
+	if errors.Is(err, SkipAll) {
+		return nil
+	}
+	return err
+}
+
+func walk(ctx context.Context, store BlobStore, prefix string, fn WalkFunc) error {
+	if walker, ok := store.(Walker); ok {
+		return walker.Walk(ctx, prefix, fn)
+	}
+
+	listing, err := store.List(ctx, prefix, ListOptions{Recursive: true})
+	if err != nil {
+		return err
+	}
+	for _, file := range listing.Files {
+		if err := fn(file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+// Package thumbnail gera miniaturas de imagens JPEG, PNG e GIF usando apenas
+// os decodificadores da biblioteca padrão.
+package thumbnail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // Registra o decodificador de GIF
+	"image/jpeg"
+	_ "image/png" // Registra o decodificador de PNG
+	"io"
+)
+
+const (
+	// MaxSourceBytes limita o tamanho do arquivo original lido para gerar a miniatura
+	MaxSourceBytes = 64 << 20
+	// MaxPixels limita as dimensões da imagem original. Uma imagem pequena no
+	// disco pode ocupar gigabytes depois de decodificada.
+	MaxPixels = 50_000_000
+
+	// samples é a quantidade de amostras por eixo tiradas de cada área da
+	// imagem original que vira um pixel da miniatura
+	samples = 4
+	quality = 80
+)
+
+var (
+	// ErrUnsupported indica um formato que não é JPEG, PNG nem GIF
+	ErrUnsupported = errors.New("formato de imagem não suportado")
+	// ErrTooLarge indica uma imagem acima de MaxSourceBytes ou MaxPixels
+	ErrTooLarge = errors.New("imagem grande demais para gerar miniatura")
+)
+
+// Generate lê uma imagem e devolve, em JPEG, uma miniatura que cabe em um
+// quadrado de size pixels, mantendo a proporção. Imagens menores não são
+// ampliadas, e áreas transparentes ficam brancas.
+func Generate(r io.Reader, size int) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxSourceBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler imagem: %w", err)
+	}
+	if len(data) > MaxSourceBytes {
+		return nil, ErrTooLarge
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		if errors.Is(err, image.ErrFormat) {
+			return nil, ErrUnsupported
+		}
+		return nil, fmt.Errorf("erro ao ler imagem: %w", err)
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, ErrUnsupported
+	}
+	if config.Width*config.Height > MaxPixels {
+		return nil, ErrTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao decodificar imagem: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, scale(src, size), &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("erro ao codificar miniatura: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// scale reduz a imagem tirando a média de algumas amostras de cada área
+// correspondente a um pixel da miniatura
+func scale(src image.Image, size int) *image.RGBA {
+	bounds := src.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+
+	dstW, dstH := srcW, srcH
+	if srcW > size || srcH > size {
+		if srcW >= srcH {
+			dstW, dstH = size, max(1, srcH*size/srcW)
+		} else {
+			dstW, dstH = max(1, srcW*size/srcH), size
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := range dstH {
+		for x := range dstW {
+			var r, g, b uint32
+			for sy := range samples {
+				srcY := bounds.Min.Y + ((y*samples+sy)*srcH)/(dstH*samples)
+				for sx := range samples {
+					srcX := bounds.Min.X + ((x*samples+sx)*srcW)/(dstW*samples)
+					// RGBA devolve cores pré-multiplicadas: somar o que falta
+					// de opacidade equivale a compor sobre fundo branco
+					cr, cg, cb, ca := src.At(srcX, srcY).RGBA()
+					r += cr + 0xffff - ca
+					g += cg + 0xffff - ca
+					b += cb + 0xffff - ca
+				}
+			}
+			n := uint32(samples * samples)
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8((r / n) >> 8),
+				G: uint8((g / n) >> 8),
+				B: uint8((b / n) >> 8),
+				A: 0xff,
+			})
+		}
+	}
+	return dst
+}
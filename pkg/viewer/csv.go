@@ -0,0 +1,106 @@
+package viewer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+)
+
+// Delimiters são os separadores reconhecidos por DetectDelimiter, em ordem de preferência
+var Delimiters = []rune{',', ';', '\t', '|'}
+
+// detectLines é a quantidade de registros examinados por DetectDelimiter
+const detectLines = 20
+
+// DetectDelimiter escolhe o separador de um CSV a partir do início do arquivo.
+// Vence o separador que aparece o mesmo número de vezes em todos os registros
+// examinados e, entre eles, o mais frequente; sem nenhum assim, o mais
+// frequente no primeiro registro. Separadores entre aspas não são contados.
+func DetectDelimiter(sample []byte) rune {
+	records := countDelimiters(sample)
+	if len(records) == 0 {
+		return Delimiters[0]
+	}
+
+	best, bestCount, bestConsistent := Delimiters[0], 0, false
+	for i, delimiter := range Delimiters {
+		count := records[0][i]
+		if count == 0 {
+			continue
+		}
+		consistent := true
+		for _, record := range records[1:] {
+			if record[i] != count {
+				consistent = false
+				break
+			}
+		}
+		if (consistent && !bestConsistent) || (consistent == bestConsistent && count > bestCount) {
+			best, bestCount, bestConsistent = delimiter, count, consistent
+		}
+	}
+	return best
+}
+
+// countDelimiters conta cada separador por registro. O último registro da
+// amostra é ignorado quando pode ter sido cortado.
+func countDelimiters(sample []byte) [][]int {
+	var records [][]int
+	current := make([]int, len(Delimiters))
+	inQuotes := false
+	for _, c := range sample {
+		switch {
+		case c == '"':
+			inQuotes = !inQuotes
+		case c == '\n' && !inQuotes:
+			records = append(records, current)
+			if len(records) == detectLines {
+				return records
+			}
+			current = make([]int, len(Delimiters))
+		case !inQuotes:
+			for i, delimiter := range Delimiters {
+				if rune(c) == delimiter {
+					current[i]++
+				}
+			}
+		}
+	}
+	if len(records) == 0 {
+		records = append(records, current)
+	}
+	return records
+}
+
+// CSV lê até max registros de uma janela de um arquivo CSV. Quando a janela
+// não chega ao fim do arquivo (eof falso), o registro que encosta no fim dela
+// pode estar cortado e fica para a próxima página. Em caso de erro, rows traz
+// os registros lidos até ele.
+func CSV(data []byte, delimiter rune, max int, eof bool) (rows [][]string, consumed int, err error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	for len(rows) < max {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		end := int(reader.InputOffset())
+		if !eof && end >= len(data) {
+			break
+		}
+		if err != nil {
+			return rows, consumed, err
+		}
+
+		for i, field := range record {
+			record[i] = toUTF8(field)
+		}
+		rows = append(rows, record)
+		consumed = end
+	}
+	return rows, consumed, nil
+}
@@ -0,0 +1,121 @@
+package viewer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetectDelimiter(t *testing.T) {
+	tests := []struct {
+		name   string
+		sample string
+		want   rune
+	}{
+		{"vazio", "", ','},
+		{"vírgula", "a,b,c\n1,2,3\n", ','},
+		{"ponto e vírgula com decimais", "nome;valor;data\nA;1,5;2024\nB;2,25;2023\n", ';'},
+		{"tabulação", "a\tb\n1\t2\n", '\t'},
+		{"barra vertical", "a|b|c\n1|2|3\n", '|'},
+		{"separadores entre aspas", "\"x;y;z\",b\n\"1;2;3\",c\n", ','},
+		{"registro com quebra de linha entre aspas", "a;\"b\nc,d,e\"\n1;2\n", ';'},
+		{"último registro cortado", "a;b\n1;2\n3,4,5", ';'},
+		{"um registro sem quebra de linha", "a|b|c", '|'},
+		{"sem separador", "texto\nsimples\n", ','},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectDelimiter([]byte(tt.sample)); got != tt.want {
+				t.Errorf("DetectDelimiter(%q) = %q, esperado %q", tt.sample, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSV(t *testing.T) {
+	// O segundo registro tem uma quebra de linha entre aspas
+	const file = "a,b\n1,\"x\ny\"\n2,z\n"
+
+	tests := []struct {
+		name         string
+		data         string
+		max          int
+		eof          bool
+		wantRows     [][]string
+		wantConsumed int
+	}{
+		{
+			name:         "arquivo inteiro",
+			data:         file,
+			max:          100,
+			eof:          true,
+			wantRows:     [][]string{{"a", "b"}, {"1", "x\ny"}, {"2", "z"}},
+			wantConsumed: len(file),
+		},
+		{
+			name:         "janela termina entre as aspas",
+			data:         file[:len("a,b\n1,\"x\n")],
+			max:          100,
+			wantRows:     [][]string{{"a", "b"}},
+			wantConsumed: len("a,b\n"),
+		},
+		{
+			name:         "janela termina logo após a quebra de linha entre aspas",
+			data:         file[:len("a,b\n1,\"x\ny")],
+			max:          100,
+			wantRows:     [][]string{{"a", "b"}},
+			wantConsumed: len("a,b\n"),
+		},
+		{
+			name:         "registro que encosta no fim da janela fica para a próxima",
+			data:         file[:len("a,b\n1,\"x\ny\"\n")],
+			max:          100,
+			wantRows:     [][]string{{"a", "b"}},
+			wantConsumed: len("a,b\n"),
+		},
+		{
+			name:         "janela passa do registro com aspas",
+			data:         file[:len("a,b\n1,\"x\ny\"\n2")],
+			max:          100,
+			wantRows:     [][]string{{"a", "b"}, {"1", "x\ny"}},
+			wantConsumed: len("a,b\n1,\"x\ny\"\n"),
+		},
+		{
+			name:         "próxima página a partir do consumido",
+			data:         file[len("a,b\n"):],
+			max:          100,
+			eof:          true,
+			wantRows:     [][]string{{"1", "x\ny"}, {"2", "z"}},
+			wantConsumed: len(file) - len("a,b\n"),
+		},
+		{
+			name:         "limite de registros",
+			data:         file,
+			max:          1,
+			eof:          true,
+			wantRows:     [][]string{{"a", "b"}},
+			wantConsumed: len("a,b\n"),
+		},
+		{
+			name:         "Latin-1",
+			data:         "caf\xe9,p\xe3o\n",
+			max:          100,
+			eof:          true,
+			wantRows:     [][]string{{"café", "pão"}},
+			wantConsumed: len("caf\xe9,p\xe3o\n"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, consumed, err := CSV([]byte(tt.data), ',', tt.max, tt.eof)
+			if err != nil {
+				t.Fatalf("CSV: %v", err)
+			}
+			if !reflect.DeepEqual(rows, tt.wantRows) {
+				t.Errorf("registros = %q, esperado %q", rows, tt.wantRows)
+			}
+			if consumed != tt.wantConsumed {
+				t.Errorf("consumido = %d, esperado %d", consumed, tt.wantConsumed)
+			}
+		})
+	}
+}
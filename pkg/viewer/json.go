@@ -0,0 +1,229 @@
+package viewer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+)
+
+// MaxNodes limita os valores exibidos por página; uma janela de 1 MB de
+// números pequenos poderia gerar centenas de milhares de nós
+const MaxNodes = 20000
+
+// errTooManyNodes interrompe a leitura ao atingir MaxNodes
+var errTooManyNodes = errors.New("valores demais para uma página")
+
+// Node é um valor JSON decodificado, exibido como uma árvore que pode ser
+// recolhida. Objetos e arrays vazios são exibidos como valores simples.
+type Node struct {
+	Label      string // nome do campo em um objeto ou posição em um array
+	Kind       string // "object", "array", "string", "number", "boolean" ou "null"
+	Value      string // valor como aparece no JSON, para os tipos simples
+	Children   []*Node
+	Depth      int
+	Incomplete bool // o valor continua além do trecho lido
+}
+
+// Open retorna o caractere que abre o objeto ou array
+func (n *Node) Open() string {
+	if n.Kind == "array" {
+		return "["
+	}
+	return "{"
+}
+
+// Close retorna o caractere que fecha o objeto ou array
+func (n *Node) Close() string {
+	if n.Kind == "array" {
+		return "]"
+	}
+	return "}"
+}
+
+// JSONPage é o resultado da leitura de uma janela de um arquivo JSON
+type JSONPage struct {
+	Values   []*Node
+	Consumed int
+	// InArray indica que a página terminou dentro do array de nível superior
+	InArray bool
+	// Partial indica que o primeiro valor não coube na janela e foi exibido em parte
+	Partial bool
+}
+
+// ArrayStart retorna a posição logo após o "[" que abre um arquivo cujo valor
+// de nível superior é um array, ou -1. Os elementos desse array são então
+// paginados como valores independentes.
+func ArrayStart(data []byte) int {
+	pos := skipSpace(data, 0)
+	if pos < len(data) && data[pos] == '[' {
+		return pos + 1
+	}
+	return -1
+}
+
+// JSON lê até max valores de uma janela de um arquivo JSON. A janela pode
+// conter vários valores seguidos (JSON Lines) ou, com inArray, elementos de
+// um array de nível superior. Quando a janela não chega ao fim do arquivo
+// (eof falso), o valor que encosta no fim dela fica para a próxima página,
+// a não ser que seja o primeiro: nesse caso ele é exibido em parte.
+func JSON(data []byte, inArray bool, max int, eof bool) (JSONPage, error) {
+	page := JSONPage{}
+	nodes := 0
+	pos := 0
+
+	for {
+		// O que vem entre os valores também conta como lido, para que a última
+		// página não deixe para trás apenas espaços ou o "]" final
+		pos, inArray = advance(data, pos, inArray)
+		page.Consumed = pos
+		if pos >= len(data) || len(page.Values) == max {
+			break
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(data[pos:]))
+		decoder.UseNumber()
+		p := &parser{decoder: decoder, nodes: nodes}
+		node, err := p.value(0)
+		end := pos + int(decoder.InputOffset())
+
+		// Depois de um valor simples dentro de um objeto ou array, o fim dos
+		// dados vira um SyntaxError em vez de io.EOF; ele é um corte quando o
+		// decoder já leu tudo o que havia na janela
+		var syntaxErr *json.SyntaxError
+		cut := errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+			(errors.As(err, &syntaxErr) && skipSpace(data, end) >= len(data))
+		if cut && eof {
+			return page, errors.New("o arquivo termina no meio de um valor")
+		}
+		if err != nil && !cut && !errors.Is(err, errTooManyNodes) {
+			return page, err
+		}
+		// O valor continua além da janela ou excede o limite de nós
+		if err != nil || (!eof && end >= len(data)) {
+			if len(page.Values) == 0 {
+				if node != nil {
+					page.Values = append(page.Values, node)
+				}
+				page.Partial = true
+			}
+			break
+		}
+
+		page.Values = append(page.Values, node)
+		nodes = p.nodes
+		pos = end
+	}
+
+	page.InArray = inArray
+	return page, nil
+}
+
+// advance pula os espaços e, dentro do array de nível superior, a vírgula
+// entre os elementos e o "]" que fecha o array
+func advance(data []byte, pos int, inArray bool) (int, bool) {
+	pos = skipSpace(data, pos)
+	if inArray && pos < len(data) && data[pos] == ',' {
+		pos = skipSpace(data, pos+1)
+	}
+	if inArray && pos < len(data) && data[pos] == ']' {
+		return skipSpace(data, pos+1), false
+	}
+	return pos, inArray
+}
+
+func skipSpace(data []byte, pos int) int {
+	for pos < len(data) {
+		switch data[pos] {
+		case ' ', '\t', '\r', '\n':
+			pos++
+		default:
+			return pos
+		}
+	}
+	return pos
+}
+
+// parser monta a árvore de um valor a partir dos tokens do decoder
+type parser struct {
+	decoder *json.Decoder
+	nodes   int
+}
+
+// value lê um valor completo. Em caso de erro, retorna o que foi lido até
+// ele, com os nós não terminados marcados como incompletos.
+func (p *parser) value(depth int) (*Node, error) {
+	if p.nodes >= MaxNodes {
+		return nil, errTooManyNodes
+	}
+	token, err := p.decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	p.nodes++
+
+	node := &Node{Depth: depth}
+	switch token := token.(type) {
+	case json.Delim:
+		node.Kind = "object"
+		if token == '[' {
+			node.Kind = "array"
+		}
+		if err := p.children(node); err != nil {
+			node.Incomplete = true
+			return node, err
+		}
+		if len(node.Children) == 0 {
+			node.Value = node.Open() + node.Close()
+		}
+	case string:
+		node.Kind = "string"
+		node.Value = quote(token)
+	case json.Number:
+		node.Kind = "number"
+		node.Value = token.String()
+	case bool:
+		node.Kind = "boolean"
+		node.Value = strconv.FormatBool(token)
+	case nil:
+		node.Kind = "null"
+		node.Value = "null"
+	}
+	return node, nil
+}
+
+// children lê os itens de um objeto ou array até o caractere que o fecha
+func (p *parser) children(node *Node) error {
+	for i := 0; p.decoder.More(); i++ {
+		label := strconv.Itoa(i)
+		if node.Kind == "object" {
+			key, err := p.decoder.Token()
+			if err != nil {
+				return err
+			}
+			label = quote(key.(string))
+		}
+
+		child, err := p.value(node.Depth + 1)
+		if child != nil {
+			child.Label = label
+			node.Children = append(node.Children, child)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	// Consome o "}" ou "]"
+	_, err := p.decoder.Token()
+	return err
+}
+
+// quote formata uma string como no JSON, sem escapar <, > e &, que o template já escapa
+func quote(s string) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	return string(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+}
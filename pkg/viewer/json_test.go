@@ -0,0 +1,175 @@
+package viewer
+
+import (
+	"testing"
+)
+
+// values resume os valores de uma página como o JSON de cada um
+func values(nodes []*Node) []string {
+	var out []string
+	for _, node := range nodes {
+		out = append(out, render(node))
+	}
+	return out
+}
+
+func render(node *Node) string {
+	if len(node.Children) == 0 {
+		return node.Value
+	}
+	s := node.Open()
+	for i, child := range node.Children {
+		if i > 0 {
+			s += ","
+		}
+		if node.Kind == "object" {
+			s += child.Label + ":"
+		}
+		s += render(child)
+	}
+	if !node.Incomplete {
+		s += node.Close()
+	}
+	return s
+}
+
+func TestJSON(t *testing.T) {
+	const array = `[{"a":1}, {"b":[2,3]}, {"c":"x"}]`
+	const lines = "{\"a\":1}\n{\"b\":2}\n{\"c\":3}\n"
+
+	tests := []struct {
+		name         string
+		data         string
+		inArray      bool
+		max          int
+		eof          bool
+		want         []string
+		wantConsumed int
+		wantInArray  bool
+		wantPartial  bool
+	}{
+		{
+			name:         "array cortado no terceiro elemento",
+			data:         array[ArrayStart([]byte(array)):len(`[{"a":1}, {"b":[2,3]}, {"c"`)],
+			inArray:      true,
+			max:          100,
+			want:         []string{`{"a":1}`, `{"b":[2,3]}`},
+			wantConsumed: len(`{"a":1}, {"b":[2,3]}, `),
+			wantInArray:  true,
+		},
+		{
+			name:         "array cortado dentro de um elemento aninhado",
+			data:         array[1:len(`[{"a":1}, {"b":[2`)],
+			inArray:      true,
+			max:          100,
+			want:         []string{`{"a":1}`},
+			wantConsumed: len(`{"a":1}, `),
+			wantInArray:  true,
+		},
+		{
+			name:         "restante do array até o fim",
+			data:         array[len(`[{"a":1}, {"b":[2,3]}, `):],
+			inArray:      true,
+			max:          100,
+			eof:          true,
+			want:         []string{`{"c":"x"}`},
+			wantConsumed: len(`{"c":"x"}]`),
+		},
+		{
+			name:         "limite de valores no array",
+			data:         array[1:],
+			inArray:      true,
+			max:          1,
+			eof:          true,
+			want:         []string{`{"a":1}`},
+			wantConsumed: len(`{"a":1}, `),
+			wantInArray:  true,
+		},
+		{
+			name:         "JSON Lines cortado no terceiro valor",
+			data:         lines[:len("{\"a\":1}\n{\"b\":2}\n{\"c\":")],
+			max:          100,
+			want:         []string{`{"a":1}`, `{"b":2}`},
+			wantConsumed: len("{\"a\":1}\n{\"b\":2}\n"),
+		},
+		{
+			name:         "JSON Lines com valor que encosta no fim da janela",
+			data:         lines[:len("{\"a\":1}\n{\"b\":2}")],
+			max:          100,
+			want:         []string{`{"a":1}`},
+			wantConsumed: len("{\"a\":1}\n"),
+		},
+		{
+			name:         "JSON Lines até o fim",
+			data:         lines,
+			max:          100,
+			eof:          true,
+			want:         []string{`{"a":1}`, `{"b":2}`, `{"c":3}`},
+			wantConsumed: len(lines),
+		},
+		{
+			name:        "primeiro valor maior que a janela",
+			data:        `{"a":[1,2,3`,
+			max:         100,
+			want:        []string{`{"a":[1,2,3`},
+			wantPartial: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := JSON([]byte(tt.data), tt.inArray, tt.max, tt.eof)
+			if err != nil {
+				t.Fatalf("JSON: %v", err)
+			}
+			got := values(page.Values)
+			if len(got) != len(tt.want) {
+				t.Fatalf("valores = %q, esperado %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("valor %d = %s, esperado %s", i, got[i], tt.want[i])
+				}
+			}
+			if page.Consumed != tt.wantConsumed {
+				t.Errorf("consumido = %d, esperado %d", page.Consumed, tt.wantConsumed)
+			}
+			if page.InArray != tt.wantInArray {
+				t.Errorf("InArray = %v, esperado %v", page.InArray, tt.wantInArray)
+			}
+			if page.Partial != tt.wantPartial {
+				t.Errorf("Partial = %v, esperado %v", page.Partial, tt.wantPartial)
+			}
+		})
+	}
+}
+
+func TestJSONTruncatedFile(t *testing.T) {
+	// No fim do arquivo, um valor cortado é um erro, e não o início da próxima página
+	if _, err := JSON([]byte(`{"a":1} {"b":`), false, 100, true); err == nil {
+		t.Error("JSON de um arquivo cortado não retornou erro")
+	}
+}
+
+func TestJSONSyntaxError(t *testing.T) {
+	// Um caractere inválido antes do fim da janela não é confundido com um corte
+	if _, err := JSON([]byte(`{"a":[2 x]} {"b":1}`), false, 100, false); err == nil {
+		t.Error("JSON inválido não retornou erro")
+	}
+}
+
+func TestArrayStart(t *testing.T) {
+	tests := []struct {
+		data string
+		want int
+	}{
+		{"[1,2]", 1},
+		{"  \n[1]", 4},
+		{`{"a":[1]}`, -1},
+		{"", -1},
+	}
+	for _, tt := range tests {
+		if got := ArrayStart([]byte(tt.data)); got != tt.want {
+			t.Errorf("ArrayStart(%q) = %d, esperado %d", tt.data, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// Package viewer interpreta trechos de arquivos CSV, JSON e texto para exibição
+// paginada. Cada função recebe uma janela do arquivo, lida por faixa, e informa
+// quantos bytes dela foram usados, que é onde a próxima página começa.
+package viewer
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+)
+
+// Text divide uma janela de um arquivo de texto em linhas. Quando a janela não
+// chega ao fim do arquivo (eof falso), a última linha, possivelmente cortada,
+// fica para a próxima página; uma linha maior que a janela inteira é dividida.
+func Text(data []byte, eof bool) (lines []string, consumed int) {
+	consumed = len(data)
+	if !eof {
+		if i := bytes.LastIndexByte(data, '\n'); i >= 0 {
+			consumed = i + 1
+		} else {
+			// Não corta um caractere UTF-8 ao meio
+			for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+				if utf8.RuneStart(data[i]) {
+					if i > 0 && !utf8.FullRune(data[i:]) {
+						consumed = i
+					}
+					break
+				}
+			}
+		}
+	}
+
+	text := strings.TrimSuffix(string(data[:consumed]), "\n")
+	if text == "" && consumed == 0 {
+		return nil, 0
+	}
+	for _, line := range strings.Split(text, "\n") {
+		lines = append(lines, toUTF8(strings.TrimSuffix(line, "\r")))
+	}
+	return lines, consumed
+}
+
+// toUTF8 converte textos que não são UTF-8 válido como se fossem Latin-1, a
+// codificação usada pelo Excel em português ao exportar CSV
+func toUTF8(s string) string {
+	if utf8.ValidString(s) {
+		return s
+	}
+	runes := make([]rune, len(s))
+	for i := 0; i < len(s); i++ {
+		runes[i] = rune(s[i])
+	}
+	return string(runes)
+}
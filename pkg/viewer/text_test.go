@@ -0,0 +1,79 @@
+package viewer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         string
+		eof          bool
+		want         []string
+		wantConsumed int
+	}{
+		{
+			name:         "vazio",
+			data:         "",
+			eof:          true,
+			want:         nil,
+			wantConsumed: 0,
+		},
+		{
+			name:         "última linha cortada fica para a próxima página",
+			data:         "primeira\nsegunda\nterc",
+			want:         []string{"primeira", "segunda"},
+			wantConsumed: len("primeira\nsegunda\n"),
+		},
+		{
+			name:         "fim do arquivo sem quebra de linha",
+			data:         "primeira\r\nsegunda",
+			eof:          true,
+			want:         []string{"primeira", "segunda"},
+			wantConsumed: len("primeira\r\nsegunda"),
+		},
+		{
+			name:         "linha maior que a janela cortada no meio de um caractere de 2 bytes",
+			data:         "ação"[:len("aç")-1],
+			want:         []string{"a"},
+			wantConsumed: len("a"),
+		},
+		{
+			name:         "linha maior que a janela cortada no meio de um caractere de 3 bytes",
+			data:         "preço €"[:len("preço €")-1],
+			want:         []string{"preço "},
+			wantConsumed: len("preço "),
+		},
+		{
+			name:         "linha maior que a janela cortada no meio de um caractere de 4 bytes",
+			data:         "ok 😀"[:len("ok 😀")-2],
+			want:         []string{"ok "},
+			wantConsumed: len("ok "),
+		},
+		{
+			name:         "linha maior que a janela terminada em um caractere completo",
+			data:         "ação",
+			want:         []string{"ação"},
+			wantConsumed: len("ação"),
+		},
+		{
+			name:         "Latin-1",
+			data:         "caf\xe9\n",
+			eof:          true,
+			want:         []string{"café"},
+			wantConsumed: len("caf\xe9\n"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, consumed := Text([]byte(tt.data), tt.eof)
+			if !reflect.DeepEqual(lines, tt.want) {
+				t.Errorf("linhas = %q, esperado %q", lines, tt.want)
+			}
+			if consumed != tt.wantConsumed {
+				t.Errorf("consumido = %d, esperado %d", consumed, tt.wantConsumed)
+			}
+		})
+	}
+}
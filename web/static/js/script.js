@@ -1,14 +1,17 @@
 let isDownloadMode = false;
+let isEditMode = false;
 
 document.addEventListener("DOMContentLoaded", () => {
   const searchInput = document.querySelector("#searchInput");
 
   if (searchInput) {
     searchInput.addEventListener("input", () => {
+      // Curingas e regex só fazem sentido na busca no servidor
+      if (document.getElementById("searchMode").value !== "contains") return;
       const query = searchInput.value.toLowerCase();
       document.querySelectorAll(".folder, .file").forEach(card => {
         const text = card.textContent.toLowerCase();
-        card.style.display = text.includes(query) ? "block" : "none";
+        card.style.display = text.includes(query) ? "" : "none";
       });
     });
   }
@@ -19,8 +22,98 @@ document.addEventListener("DOMContentLoaded", () => {
   
   // Checar se temos folderActionButtons (pode não existir na raiz da conta padrão)
   const folderActionButtons = document.getElementById("folderActionButtons");
+
+  // Com o cliente tus disponível, os arquivos são enviados por /uploads e
+  // podem ser retomados; sem ele, o formulário segue para /upload normalmente
+  const uploadForm = document.getElementById("uploadForm");
+  if (uploadForm && window.tus && tus.isSupported) {
+    uploadForm.addEventListener("submit", event => {
+      event.preventDefault();
+      uploadResumable(uploadForm);
+    });
+  }
+
+  // Com "Em subpastas" marcado, ou nos modos curinga e regex, Enter inicia a
+  // busca recursiva no servidor
+  const searchForm = document.getElementById("searchForm");
+  if (searchForm) {
+    searchForm.addEventListener("submit", () => {
+      const mode = document.getElementById("searchMode").value;
+      if (mode !== "contains" || document.getElementById("searchRecursive").checked) {
+        searchRecursive(searchInput.value.trim(), mode, prefix);
+        return;
+      }
+      // Sem subpastas, Enter filtra a pasta inteira no servidor, e não só a
+      // página exibida
+      const params = new URLSearchParams(window.location.search);
+      ["marker", "prev", "page"].forEach(key => params.delete(key));
+      params.set("q", searchInput.value.trim());
+      if (!params.get("q")) params.delete("q");
+      window.location.search = params.toString();
+    });
+  }
 });
 
+// Tamanho de cada PATCH; múltiplo dos blocos de 8 MB gravados pelo servidor
+const UPLOAD_CHUNK_SIZE = 32 * 1024 * 1024;
+
+async function uploadResumable(form) {
+  const files = Array.from(form.querySelector("input[type=file]").files);
+  const prefix = form.querySelector("input[name=prefix]").value;
+  const progress = document.getElementById("uploadProgress");
+  const status = document.getElementById("uploadStatus");
+  const bar = progress.querySelector(".progress-bar");
+  const submit = form.querySelector("button[type=submit]");
+
+  submit.disabled = true;
+  progress.style.display = "block";
+
+  try {
+    for (const [index, file] of files.entries()) {
+      status.textContent = `Enviando ${file.name} (${index + 1} de ${files.length})`;
+      bar.style.width = "0%";
+      await uploadFile(file, prefix, percent => {
+        bar.style.width = percent + "%";
+      });
+    }
+    window.location.href = "/?prefix=" + encodeURIComponent(prefix);
+  } catch (err) {
+    status.textContent = "Falha no envio. Envie o mesmo arquivo novamente para continuar de onde parou.";
+    bar.classList.add("bg-danger");
+    submit.disabled = false;
+    console.error(err);
+  }
+}
+
+function uploadFile(file, prefix, onProgress) {
+  return new Promise((resolve, reject) => {
+    const upload = new tus.Upload(file, {
+      endpoint: "/uploads",
+      chunkSize: UPLOAD_CHUNK_SIZE,
+      retryDelays: [0, 1000, 3000, 5000, 10000, 20000],
+      metadata: {
+        filename: file.name,
+        filetype: file.type,
+        prefix: prefix,
+      },
+      // Envios anteriores do mesmo arquivo para a mesma pasta são retomados
+      fingerprint: async (file) =>
+        ["fileblobs", prefix, file.name, file.size, file.lastModified].join("-"),
+      removeFingerprintOnSuccess: true,
+      onProgress: (sent, total) => onProgress(total ? Math.floor((sent / total) * 100) : 100),
+      onSuccess: resolve,
+      onError: reject,
+    });
+
+    upload.findPreviousUploads().then(previous => {
+      if (previous.length > 0) {
+        upload.resumeFromPreviousUpload(previous[0]);
+      }
+      upload.start();
+    });
+  });
+}
+
 function showDownloadConfirm() {
   isDownloadMode = true;
 
@@ -62,7 +155,9 @@ function cancelDownload() {
 
 function handleFolderClick(el) {
   const path = el.getAttribute("data-path");
-  if (!isDownloadMode) {
+  if (isEditMode) {
+    el.classList.toggle("selected");
+  } else if (!isDownloadMode) {
     window.location.href = "/?prefix=" + path + "/";
   } else {
     window.location.href = "/download-folder?path=" + path;
@@ -72,19 +167,45 @@ function handleFolderClick(el) {
 function toggleFileSelection(el, event) {
   const checkbox = el.querySelector(".file-checkbox");
 
-  if (isDownloadMode) {
+  if (isDownloadMode || isEditMode) {
     event.preventDefault();
     checkbox.checked = !checkbox.checked;
     el.classList.toggle("selected", checkbox.checked);
+  } else if (el.dataset.preview || el.dataset.viewer) {
+    showPreview(el.getAttribute("data-path"), Boolean(el.dataset.viewer));
   } else {
     const path = el.getAttribute("data-path");
     window.location.href = "/download?path=" + encodeURIComponent(path);
   }
 }
 
+// Painel de pré-visualização ao lado da listagem; /preview envia o arquivo
+// inline com uma CSP que impede a execução de scripts. CSV, JSON e texto
+// abrem no visualizador paginado (/view), que lê só a página exibida.
+function showPreview(path, paged) {
+  const src = (paged ? "/view?path=" : "/preview?path=") + encodeURIComponent(path);
+  document.getElementById("previewName").textContent = path.split("/").pop();
+  document.getElementById("previewDownload").href = "/download?path=" + encodeURIComponent(path);
+  document.getElementById("previewOpen").href = src;
+  document.getElementById("previewFrame").src = src;
+  document.getElementById("previewPane").style.display = "flex";
+  document.body.classList.add("preview-open");
+
+  document.querySelectorAll(".file.previewing").forEach(el => el.classList.remove("previewing"));
+  document.querySelector(`.file[data-path="${CSS.escape(path)}"]`)?.classList.add("previewing");
+}
+
+function closePreview() {
+  document.getElementById("previewPane").style.display = "none";
+  // Limpar o iframe interrompe áudios e vídeos em reprodução
+  document.getElementById("previewFrame").src = "about:blank";
+  document.body.classList.remove("preview-open");
+  document.querySelectorAll(".file.previewing").forEach(el => el.classList.remove("previewing"));
+}
+
 
 function selectAll() {
-  document.querySelectorAll(".file-checkbox").forEach(cb => {
+  document.querySelectorAll(".file-list .file-checkbox").forEach(cb => {
     cb.checked = true;
     cb.closest(".file").classList.add("selected");
   });
@@ -122,3 +243,434 @@ function downloadSelected() {
   document.body.appendChild(form);
   form.submit();
 }
+
+function showEditMode() {
+  isEditMode = true;
+  document.body.classList.add("edit-mode");
+
+  const folderActionButtons = document.getElementById("folderActionButtons");
+  const editButtons = document.getElementById("editButtons");
+
+  if (folderActionButtons) folderActionButtons.style.display = "none";
+  if (editButtons) editButtons.style.display = "flex";
+
+  document.querySelectorAll(".file").forEach(el => {
+    el.classList.add("show-checkboxes");
+  });
+
+  document.querySelectorAll(".folder").forEach(folder => {
+    folder.classList.add("edit-folder");
+  });
+}
+
+function cancelEditMode() {
+  isEditMode = false;
+  document.body.classList.remove("edit-mode");
+
+  const folderActionButtons = document.getElementById("folderActionButtons");
+  const editButtons = document.getElementById("editButtons");
+
+  if (editButtons) editButtons.style.display = "none";
+  if (folderActionButtons) folderActionButtons.style.display = "flex";
+
+  document.querySelectorAll(".file").forEach(el => {
+    el.classList.remove("show-checkboxes", "selected");
+    el.querySelector(".file-checkbox").checked = false;
+  });
+
+  document.querySelectorAll(".folder").forEach(folder => {
+    folder.classList.remove("edit-folder", "selected");
+  });
+}
+
+// Monta o corpo de /delete e /move com os arquivos e pastas selecionados
+function selectionRequestBody(confirm) {
+  const body = new URLSearchParams();
+  document.querySelectorAll(".file-checkbox:checked").forEach(cb => {
+    body.append("paths", cb.value);
+  });
+  document.querySelectorAll(".folder.selected").forEach(folder => {
+    body.append("folders", folder.getAttribute("data-path"));
+  });
+  if (confirm) {
+    body.append("confirm", "1");
+  }
+  return body;
+}
+
+async function postDelete(confirm) {
+  const response = await fetch("/delete", {
+    method: "POST",
+    headers: { Accept: "application/json" },
+    body: selectionRequestBody(confirm),
+  });
+  const data = await response.json();
+  if (!response.ok) {
+    throw new Error(data.error || "Erro ao excluir arquivos");
+  }
+  return data;
+}
+
+// Primeira etapa: lista o que será excluído e pede confirmação
+async function deleteSelected() {
+  const body = selectionRequestBody(false);
+  if (!body.has("paths") && !body.has("folders")) {
+    alert("Selecione ao menos 1 arquivo ou pasta.");
+    return;
+  }
+
+  try {
+    const preview = await postDelete(false);
+    const items = document.getElementById("deleteItems");
+    items.innerHTML = "";
+    preview.items.forEach(path => {
+      const li = document.createElement("li");
+      li.textContent = path;
+      items.appendChild(li);
+    });
+
+    let summary = `${preview.total} arquivo(s) serão excluídos permanentemente.`;
+    if (preview.truncated) {
+      summary += ` Exibindo os primeiros ${preview.items.length}.`;
+    }
+    document.getElementById("deleteSummary").textContent = summary;
+
+    const confirmButton = document.getElementById("deleteConfirmButton");
+    confirmButton.style.display = preview.total > 0 ? "inline-block" : "none";
+    confirmButton.disabled = false;
+
+    bootstrap.Modal.getOrCreateInstance(document.getElementById("deleteModal")).show();
+  } catch (err) {
+    alert(err.message);
+  }
+}
+
+// Segunda etapa: exclui e mostra o resultado de cada arquivo
+async function confirmDelete() {
+  const confirmButton = document.getElementById("deleteConfirmButton");
+  confirmButton.disabled = true;
+
+  try {
+    const result = await postDelete(true);
+    const items = document.getElementById("deleteItems");
+    items.innerHTML = "";
+    result.results.forEach(item => {
+      const li = document.createElement("li");
+      li.textContent = item.deleted ? `✓ ${item.path}` : `✗ ${item.path}: ${item.error}`;
+      li.className = item.deleted ? "text-success" : "text-danger";
+      items.appendChild(li);
+    });
+
+    document.getElementById("deleteSummary").textContent =
+      `${result.deleted} arquivo(s) excluído(s), ${result.failed} falha(s).`;
+    confirmButton.style.display = "none";
+
+    document.getElementById("deleteModal").addEventListener("hidden.bs.modal", () => {
+      window.location.reload();
+    }, { once: true });
+  } catch (err) {
+    alert(err.message);
+    confirmButton.disabled = false;
+  }
+}
+
+// Itens selecionados no modo de edição, usados para renomear
+function selectedItemCount() {
+  return document.querySelectorAll(".file-checkbox:checked, .folder.selected").length;
+}
+
+// Textos de cada modo do modal de movimentação; os modos com transfer levam
+// os arquivos para uma pasta de destino
+const MOVE_MODES = {
+  rename: { title: "Renomear", url: "/move", done: "renomeado(s)", transfer: true },
+  move: { title: "Mover", url: "/move", done: "movido(s)", transfer: true },
+  copy: { title: "Copiar para Conta", url: "/copy-to-account", done: "copiado(s)", transfer: true },
+  tier: { title: "Alterar Camada", url: "/set-tier", done: "alterado(s)" },
+  tags: { title: "Aplicar Tags", url: "/bulk-tags", done: "atualizado(s)" },
+};
+
+let moveMode = "move";
+
+function showMoveModal(mode) {
+  const count = selectedItemCount();
+  if (count === 0) {
+    alert("Selecione ao menos 1 arquivo ou pasta.");
+    return;
+  }
+  if (mode === "rename" && count !== 1) {
+    alert("Selecione apenas 1 item para renomear.");
+    return;
+  }
+  moveMode = mode;
+
+  const nameInput = document.getElementById("moveName");
+  nameInput.value = "";
+  if (mode === "rename") {
+    const selected = document.querySelector(".file-checkbox:checked")?.value ||
+      document.querySelector(".folder.selected").getAttribute("data-path");
+    nameInput.value = selected.split("/").filter(Boolean).pop();
+  }
+
+  document.getElementById("moveModalLabel").textContent = MOVE_MODES[mode].title;
+  document.getElementById("moveNameGroup").style.display = mode === "rename" ? "block" : "none";
+  document.getElementById("copyAccountGroup").style.display = mode === "copy" ? "block" : "none";
+  document.getElementById("moveDestinationGroup").style.display = MOVE_MODES[mode].transfer ? "block" : "none";
+  document.getElementById("tierGroup").style.display = mode === "tier" ? "block" : "none";
+  document.getElementById("tagsGroup").style.display = mode === "tags" ? "block" : "none";
+  document.getElementById("moveForm").style.display = "block";
+  document.getElementById("moveProgress").style.display = "none";
+
+  const confirmButton = document.getElementById("moveConfirmButton");
+  confirmButton.textContent = MOVE_MODES[mode].title;
+  confirmButton.style.display = "inline-block";
+  confirmButton.disabled = false;
+
+  bootstrap.Modal.getOrCreateInstance(document.getElementById("moveModal")).show();
+}
+
+// Inicia a tarefa (movimentação, cópia, camada ou tags) e acompanha o progresso até o fim
+async function confirmMove() {
+  const confirmButton = document.getElementById("moveConfirmButton");
+  confirmButton.disabled = true;
+
+  const body = selectionRequestBody(false);
+  if (MOVE_MODES[moveMode].transfer) {
+    body.append("destination", document.getElementById("moveDestination").value);
+  }
+  if (moveMode === "tier") {
+    body.append("tier", document.getElementById("tierSelect").value);
+    body.append("priority", document.getElementById("tierPriority").value);
+  }
+  if (moveMode === "tags") {
+    body.append("set", document.getElementById("tagsSet").value);
+    body.append("remove", document.getElementById("tagsRemove").value);
+  }
+  if (moveMode === "rename") {
+    body.append("name", document.getElementById("moveName").value);
+  }
+  if (moveMode === "copy") {
+    body.append("destinationAccount", document.getElementById("copyAccount").value);
+    body.append("destinationContainer", document.getElementById("copyContainer").value);
+  }
+  if (MOVE_MODES[moveMode].transfer && document.getElementById("moveOverwrite").checked) {
+    body.append("overwrite", "1");
+  }
+
+  try {
+    const response = await fetch(MOVE_MODES[moveMode].url, {
+      method: "POST",
+      headers: { Accept: "application/json" },
+      body: body,
+    });
+    let status = await response.json();
+    if (!response.ok) {
+      throw new Error(status.error || "Erro ao processar arquivos");
+    }
+
+    document.getElementById("moveForm").style.display = "none";
+    document.getElementById("moveProgress").style.display = "block";
+    confirmButton.style.display = "none";
+
+    document.getElementById("moveModal").addEventListener("hidden.bs.modal", () => {
+      window.location.reload();
+    }, { once: true });
+
+    while (true) {
+      renderMoveStatus(status);
+      if (status.status === "done") {
+        break;
+      }
+      await new Promise(resolve => setTimeout(resolve, 1000));
+      const poll = await fetch("/job-status?id=" + encodeURIComponent(status.id), {
+        headers: { Accept: "application/json" },
+      });
+      status = await poll.json();
+      if (!poll.ok) {
+        throw new Error(status.error || "Erro ao consultar tarefa");
+      }
+    }
+  } catch (err) {
+    alert(err.message);
+    confirmButton.disabled = false;
+  }
+}
+
+function renderMoveStatus(status) {
+  const processed = status.completed + status.failed;
+  const percent = status.total ? Math.floor((processed / status.total) * 100) : 100;
+  document.querySelector("#moveProgress .progress-bar").style.width = percent + "%";
+
+  let summary = `${status.completed} de ${status.total} arquivo(s) ${MOVE_MODES[moveMode].done}`;
+  if (status.failed > 0) {
+    summary += `, ${status.failed} falha(s)`;
+  }
+  if (status.status === "done" && status.failed > 0) {
+    summary += MOVE_MODES[moveMode].transfer
+      ? ". Os arquivos com falha continuam na origem; repita a operação para processá-los."
+      : ". Repita a operação para processar os arquivos com falha.";
+  }
+  document.getElementById("moveSummary").textContent = summary;
+
+  const errors = document.getElementById("moveErrors");
+  errors.innerHTML = "";
+  (status.errors || []).forEach(item => {
+    const li = document.createElement("li");
+    li.textContent = `${item.path}: ${item.error}`;
+    errors.appendChild(li);
+  });
+}
+
+// Busca recursiva: /search envia um resultado por linha (NDJSON) à medida que
+// a listagem avança; abortar o fetch cancela a busca no servidor
+let searchController = null;
+
+async function searchRecursive(query, mode, prefix) {
+  if (!query) return;
+  cancelSearch();
+
+  const controller = new AbortController();
+  searchController = controller;
+
+  const results = document.getElementById("searchResults");
+  const status = document.getElementById("searchStatus");
+  const cancelButton = document.getElementById("searchCancelButton");
+  results.innerHTML = "";
+  status.textContent = `Buscando "${query}"...`;
+  cancelButton.style.display = "inline-block";
+  document.getElementById("searchPanel").style.display = "block";
+
+  let count = 0;
+  try {
+    const params = new URLSearchParams({ q: query, mode, prefix });
+    const response = await fetch("/search?" + params, {
+      headers: { Accept: "application/json" },
+      signal: controller.signal
+    });
+    if (!response.ok) {
+      const data = await response.json().catch(() => ({}));
+      throw new Error(data.error || "Erro na busca");
+    }
+
+    const reader = response.body.getReader();
+    const decoder = new TextDecoder();
+    let buffer = "";
+    for (;;) {
+      const { value, done } = await reader.read();
+      if (done) break;
+      buffer += decoder.decode(value, { stream: true });
+
+      const lines = buffer.split("\n");
+      buffer = lines.pop();
+      for (const line of lines) {
+        if (!line) continue;
+        const item = JSON.parse(line);
+        if (item.done) {
+          status.textContent = searchSummaryText(item);
+        } else {
+          results.appendChild(searchResultRow(item));
+          count++;
+          status.textContent = `Buscando "${query}"... ${count} resultado(s)`;
+        }
+      }
+    }
+  } catch (err) {
+    status.textContent = err.name === "AbortError"
+      ? `Busca cancelada, ${count} resultado(s)`
+      : err.message;
+  } finally {
+    if (searchController === controller) {
+      searchController = null;
+      cancelButton.style.display = "none";
+    }
+  }
+}
+
+function searchSummaryText(summary) {
+  let text = `${summary.count} resultado(s) em ${summary.scanned} arquivo(s)`;
+  if (summary.truncated) {
+    text += "; limite de resultados atingido, refine a busca";
+  }
+  if (summary.error) {
+    text += `. ${summary.error}`;
+  }
+  return text;
+}
+
+// Cada resultado tem a mesma marcação de seleção da listagem (.file e
+// .file-checkbox), para que o download e as operações em lote os incluam
+function searchResultRow(item) {
+  const row = document.createElement("tr");
+  row.className = "file";
+  row.dataset.path = item.path;
+
+  const checkbox = document.createElement("input");
+  checkbox.type = "checkbox";
+  checkbox.className = "file-checkbox";
+  checkbox.value = item.path;
+  checkbox.addEventListener("change", () => row.classList.toggle("selected", checkbox.checked));
+  const checkboxCell = document.createElement("td");
+  checkboxCell.appendChild(checkbox);
+
+  // Clicar na linha marca o resultado; o link continua baixando o arquivo
+  row.addEventListener("click", event => {
+    if (event.target === checkbox || event.target.tagName === "A") return;
+    checkbox.checked = !checkbox.checked;
+    row.classList.toggle("selected", checkbox.checked);
+  });
+
+  const pathCell = document.createElement("td");
+  const link = document.createElement("a");
+  link.href = "/download?path=" + encodeURIComponent(item.path);
+  link.textContent = item.path;
+  pathCell.appendChild(link);
+
+  const folder = item.path.substring(0, item.path.lastIndexOf("/") + 1);
+  if (folder) {
+    const folderLink = document.createElement("a");
+    folderLink.href = "/?prefix=" + encodeURIComponent(folder);
+    folderLink.className = "ms-2 small";
+    folderLink.textContent = "abrir pasta";
+    pathCell.appendChild(folderLink);
+  }
+
+  const sizeCell = document.createElement("td");
+  sizeCell.className = "text-end";
+  sizeCell.textContent = formatBytes(item.size);
+
+  const dateCell = document.createElement("td");
+  dateCell.textContent = item.lastModified ? new Date(item.lastModified).toLocaleString("pt-BR") : "";
+
+  row.append(checkboxCell, pathCell, sizeCell, dateCell);
+  return row;
+}
+
+function selectSearchResults() {
+  document.querySelectorAll("#searchResults .file-checkbox").forEach(cb => {
+    cb.checked = true;
+    cb.closest(".file").classList.add("selected");
+  });
+}
+
+function cancelSearch() {
+  if (searchController) {
+    searchController.abort();
+  }
+}
+
+function closeSearch() {
+  cancelSearch();
+  // Resultados fechados não podem continuar selecionados para as operações em lote
+  document.getElementById("searchResults").innerHTML = "";
+  document.getElementById("searchPanel").style.display = "none";
+}
+
+// Mesmo formato de tamanho usado na listagem (B, KB, MB, ...)
+function formatBytes(size) {
+  const units = ["B", "KB", "MB", "GB", "TB", "PB"];
+  let unit = 0;
+  while (size >= 1024 && unit < units.length - 1) {
+    size /= 1024;
+    unit++;
+  }
+  return unit === 0 ? `${size} B` : `${size.toFixed(1)} ${units[unit]}`;
+}